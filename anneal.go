@@ -0,0 +1,101 @@
+package neural
+
+import (
+	"context"
+	"math"
+	"math/rand"
+)
+
+// Optimizes a single network by simulated annealing, as a cheaper alternative to a Trainer for small problems. Every
+// iteration scores a mutated copy of the current network, which replaces the current network if it scores at least as
+// well, or otherwise with a probability of exp(-loss/temperature), where the temperature decays from Temperature by a
+// factor of Cooling per iteration. Accepting worse networks early on lets the search leave local optima.
+type AnnealOptimizer struct {
+	// The source of all randomness used by the optimizer.
+	Rand *rand.Rand
+	// The task that networks are scored on, with the sum of their scores over Episodes episodes.
+	Environment Environment
+	Episodes    int
+	// The rarity that copies are mutated with (see InferredLayer.Mutate).
+	Rarity int
+	// The initial temperature, in units of score, and the factor it's multiplied by after every iteration.
+	Temperature float64
+	Cooling     float64
+	// The number of mutated copies to score. Run stops after this many iterations.
+	Iterations int
+
+	// If set, called after every iteration with the scores of the current and best networks.
+	OnIteration func(i int, current, best int)
+}
+
+// Creates an optimizer where all randomness derives from seed, which tries 10000 mutations with a temperature that
+// decays from 100 to about 0.005. The score of a network is its sum over 100 episodes of env.
+func NewAnnealOptimizer(seed int64, env Environment) *AnnealOptimizer {
+	return &AnnealOptimizer{
+		Rand:        rand.New(rand.NewSource(seed)),
+		Environment: env,
+		Episodes:    100,
+		Rarity:      100,
+		Temperature: 100,
+		Cooling:     0.999,
+		Iterations:  10000,
+	}
+}
+
+// Anneals net, which must read from a single input layer, and returns the highest scoring network seen. The input
+// layer is overwritten with the input of every episode, and net itself is never modified. If ctx is done before the last
+// iteration, the best network so far is returned with ctx.Err().
+func (a *AnnealOptimizer) Run(ctx context.Context, net *InferredLayer) (ScoredLayer, error) {
+	in, err := inputOf(net)
+	if err != nil {
+		return ScoredLayer{}, err
+	}
+	score, err := a.score(in, net)
+	if err != nil {
+		return ScoredLayer{}, err
+	}
+	current := ScoredLayer{net, score}
+	best := current
+	temperature := a.Temperature
+	for i := 0; i < a.Iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return best, err
+		}
+		candidate := current.CopyInferred()
+		candidate.Mutate(a.Rand, a.Rarity)
+		score, err := a.score(in, candidate)
+		if err != nil {
+			return best, err
+		}
+		if score >= current.Score || temperature > 0 && a.Rand.Float64() < math.Exp(float64(score-current.Score)/temperature) {
+			current = ScoredLayer{candidate, score}
+			if score > best.Score {
+				best = current
+			}
+		}
+		temperature *= a.Cooling
+		if a.OnIteration != nil {
+			a.OnIteration(i, current.Score, best.Score)
+		}
+	}
+	return best, nil
+}
+
+// Returns the sum of a network's scores over Episodes episodes, or FailedScore if the environment can't score it. An
+// error means the environment produced an input that doesn't fit in.
+func (a *AnnealOptimizer) score(in StaticLayer, l *InferredLayer) (int, error) {
+	out := make([]byte, l.Size())
+	var sum int
+	for i := 0; i < a.Episodes; i++ {
+		if err := in.Set(a.Environment.Reset(a.Rand)); err != nil {
+			return 0, err
+		}
+		l.GetValuesInto(out)
+		score, err := a.Environment.Score(a.Rand, in, out)
+		if err != nil {
+			return FailedScore, nil
+		}
+		sum += score
+	}
+	return sum, nil
+}
@@ -0,0 +1,49 @@
+package neural
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Evaluates a network for each of the inputs and returns the outputs. The network must read from a single input
+// layer, which is overwritten with each input in turn and restored to its original values afterwards. Since the input
+// layer is usually shared by all copies of a network, GetValuesBatch must not be called while other copies are being
+// evaluated. Recurrent layers advance one step per input.
+func GetValuesBatch(l Layer, inputs [][]byte) ([][]byte, error) {
+	in, err := inputOf(l)
+	if err != nil {
+		return nil, err
+	}
+	saved := in.Clone()
+	defer in.Set(saved)
+	// All outputs share one allocation.
+	size := l.Size()
+	buf := make([]byte, len(inputs)*size)
+	outputs := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		if err := in.Set(input); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		outputs[i] = buf[i*size : (i+1)*size : (i+1)*size]
+		copy(outputs[i], valuesOf(l))
+	}
+	return outputs, nil
+}
+
+// Returns the input layer that a network reads from, or an error if there isn't exactly one.
+func inputOf(l Layer) (StaticLayer, error) {
+	var inputs []StaticLayer
+	Walk(l, func(depth int, l Layer) {
+		if s, ok := l.(StaticLayer); ok {
+			inputs = append(inputs, s)
+		}
+	})
+	switch len(inputs) {
+	case 0:
+		return nil, errors.New("network has no input layer")
+	case 1:
+		return inputs[0], nil
+	default:
+		return nil, fmt.Errorf("network has %d input layers", len(inputs))
+	}
+}
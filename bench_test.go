@@ -0,0 +1,188 @@
+package neural
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// A network shape: the input size, and the number and size of the inferred layers on top of it.
+type benchShape struct {
+	name               string
+	input, depth, size int
+}
+
+// A network the size of the demo's and a larger one.
+var benchShapes = []benchShape{
+	{"demo", 9, 11, 9},
+	{"large", 64, 20, 64},
+}
+
+func newBenchNetwork(r *rand.Rand, in StaticLayer, s benchShape) *InferredLayer {
+	var l Layer = in
+	for i := 0; i < s.depth-1; i++ {
+		l = NewFullyConnectedLayerWithRand(r, l, s.size)
+	}
+	return NewFullyConnectedLayerWithRand(r, l, s.size)
+}
+
+// Runs f as a sub-benchmark for every shape, reporting allocations.
+func benchShapesRun(b *testing.B, f func(b *testing.B, s benchShape)) {
+	for _, s := range benchShapes {
+		b.Run(s.name, func(b *testing.B) {
+			b.ReportAllocs()
+			f(b, s)
+		})
+	}
+}
+
+func BenchmarkGetValues(b *testing.B) {
+	benchShapesRun(b, func(b *testing.B, s benchShape) {
+		r := rand.New(rand.NewSource(1))
+		in := make(StaticLayer, s.input)
+		l := newBenchNetwork(r, in, s)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Change the input so that the cached values can't be reused.
+			in[0] = byte(i)
+			l.GetValues()
+		}
+	})
+}
+
+func BenchmarkMutate(b *testing.B) {
+	benchShapesRun(b, func(b *testing.B, s benchShape) {
+		r := rand.New(rand.NewSource(1))
+		l := newBenchNetwork(r, make(StaticLayer, s.input), s)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.Mutate(r, 1000)
+		}
+	})
+}
+
+func BenchmarkCopy(b *testing.B) {
+	benchShapesRun(b, func(b *testing.B, s benchShape) {
+		l := newBenchNetwork(rand.New(rand.NewSource(1)), make(StaticLayer, s.input), s)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.Copy()
+		}
+	})
+}
+
+func BenchmarkGeneration(b *testing.B) {
+	benchShapesRun(b, func(b *testing.B, s benchShape) {
+		t := newBenchTrainer(s)
+		// The first generation also creates the population.
+		t.Step()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			t.Step()
+		}
+	})
+}
+
+// Returns a trainer that evolves networks of shape s to reproduce random inputs.
+func newBenchTrainer(s benchShape) *Trainer {
+	t := NewTrainer(1)
+	t.Input = make(StaticLayer, s.input)
+	t.NewNetwork = func(r *rand.Rand) *InferredLayer {
+		return newBenchNetwork(r, t.Input, s)
+	}
+	t.Episode = func(r *rand.Rand, input StaticLayer) {
+		r.Read(input)
+	}
+	t.Fitness = benchFitness{}
+	t.Episodes = 10
+	return t
+}
+
+// Scores the output by how many bits it shares with the input, which works for any shape.
+type benchFitness struct{}
+
+func (benchFitness) Evaluate(r *rand.Rand, input StaticLayer, output []byte) (int, error) {
+	n := min(len(input), len(output))
+	d, err := HammingDistance(input[:n], output[:n])
+	return -d, err
+}
+
+func BenchmarkGetValuesInto(b *testing.B) {
+	benchShapesRun(b, func(b *testing.B, s benchShape) {
+		r := rand.New(rand.NewSource(1))
+		in := make(StaticLayer, s.input)
+		l := newBenchNetwork(r, in, s)
+		out := make([]byte, l.Size())
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			in[0] = byte(i)
+			l.GetValuesInto(out)
+		}
+	})
+}
+
+// Evaluates a 50-layer network, which computes every layer once per evaluation when the input changes and none of them
+// when it doesn't.
+func BenchmarkGetValuesDeep(b *testing.B) {
+	s := benchShape{"deep", 16, 50, 16}
+	for _, changeInput := range []bool{true, false} {
+		name := "cached"
+		if changeInput {
+			name = "uncached"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			in := make(StaticLayer, s.input)
+			l := newBenchNetwork(rand.New(rand.NewSource(1)), in, s)
+			out := make([]byte, l.Size())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if changeInput {
+					in[0] = byte(i)
+				}
+				l.GetValuesInto(out)
+			}
+		})
+	}
+}
+
+// Evaluates inferred layers reading from layers that don't cache their values, whose values are written into pooled
+// buffers instead of allocated.
+func BenchmarkGetValuesPooled(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	in := make(StaticLayer, 16)
+	concat := NewFullyConnectedLayerWithRand(r, NewConcatLayer(in, &NotLayer{Left: in}), 16)
+	pool, err := NewMaxPoolLayer(concat, 2)
+	if err != nil {
+		b.Fatal(err)
+	}
+	l := NewFullyConnectedLayerWithRand(r, pool, 16)
+	out := make([]byte, l.Size())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		in[0] = byte(i)
+		l.GetValuesInto(out)
+	}
+}
+
+// Runs full generations, including evaluation and building the offspring, with one worker and with one per CPU.
+func BenchmarkStep(b *testing.B) {
+	workers := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		workers = append(workers, n)
+	}
+	for _, workers := range workers {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			t := newBenchTrainer(benchShapes[0])
+			t.Workers = workers
+			t.Step()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				t.Step()
+			}
+		})
+	}
+}
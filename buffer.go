@@ -0,0 +1,50 @@
+package neural
+
+import (
+	"sync"
+)
+
+// Pools of value buffers, keyed by size, for layers that can't keep their values around between evaluations.
+var buffers sync.Map // map[int]*sync.Pool
+
+// Returns a zeroed buffer of n bytes that the caller owns until it's passed to putBuffer.
+func getBuffer(n int) *[]byte {
+	p, ok := buffers.Load(n)
+	if !ok {
+		p, _ = buffers.LoadOrStore(n, &sync.Pool{
+			New: func() any {
+				b := make([]byte, n)
+				return &b
+			},
+		})
+	}
+	b := p.(*sync.Pool).Get().(*[]byte)
+	clear(*b)
+	return b
+}
+
+// Returns a buffer from getBuffer to its pool. A nil buffer is ignored.
+func putBuffer(b *[]byte) {
+	if b == nil {
+		return
+	}
+	if p, ok := buffers.Load(len(*b)); ok {
+		p.(*sync.Pool).Put(b)
+	}
+}
+
+// Returns the values of a left layer for reading, and the pooled buffer holding them, if any, which must be passed to
+// putBuffer once the values have been read. Layers that can write their values into a buffer get a pooled one, so
+// evaluating them doesn't allocate.
+func leftValues(l Layer) ([]byte, *[]byte) {
+	switch v := l.(type) {
+	case interface{ values() []byte }:
+		return v.values(), nil
+	case interface{ valuesInto(dst []byte) }:
+		b := getBuffer(l.Size())
+		v.valuesInto(*b)
+		return *b, b
+	default:
+		return l.GetValues(), nil
+	}
+}
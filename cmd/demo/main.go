@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blixt/neural"
+)
+
+// The hyperparameters of a run. The defaults are those of NewTrainer with 10 hidden layers of 9 nodes.
+type Config struct {
+	// The seed of the trainer, or 0 to use the current time.
+	Seed int64
+	// The number of hidden layers and the number of nodes in each of them.
+	Layers, Width int
+	Population    int
+	Elites        int
+	Episodes      int
+	Offspring     offspringFlag
+}
+
+// A list of offspring as comma-separated copies:rarity pairs, e.g. "10:5000,5:1000".
+type offspringFlag []neural.Offspring
+
+func (f *offspringFlag) String() string {
+	pairs := make([]string, len(*f))
+	for i, o := range *f {
+		pairs[i] = fmt.Sprintf("%d:%d", o.Copies, o.Rarity)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f *offspringFlag) Set(s string) error {
+	var offspring []neural.Offspring
+	for _, pair := range strings.Split(s, ",") {
+		copies, rarity, ok := strings.Cut(pair, ":")
+		if !ok {
+			return fmt.Errorf("%q is not of the form copies:rarity", pair)
+		}
+		c, err := strconv.Atoi(copies)
+		if err != nil {
+			return err
+		}
+		r, err := strconv.Atoi(rarity)
+		if err != nil {
+			return err
+		}
+		if r < 1 {
+			return fmt.Errorf("rarity %d must be at least 1", r)
+		}
+		offspring = append(offspring, neural.Offspring{Copies: c, Rarity: r})
+	}
+	*f = offspring
+	return nil
+}
+
+func main() {
+	defaults := neural.NewTrainer(0)
+	c := Config{
+		Layers:     10,
+		Width:      9,
+		Population: defaults.PopulationSize,
+		Elites:     defaults.EliteCount,
+		Episodes:   defaults.Episodes,
+		Offspring:  defaults.Offspring,
+	}
+	flag.Int64Var(&c.Seed, "seed", c.Seed, "seed of the trainer, or 0 to use the current time")
+	flag.IntVar(&c.Layers, "layers", c.Layers, "number of hidden layers")
+	flag.IntVar(&c.Width, "width", c.Width, "number of nodes in each hidden layer")
+	flag.IntVar(&c.Population, "population", c.Population, "number of networks in the population")
+	flag.IntVar(&c.Elites, "elites", c.Elites, "number of top networks that survive each generation unchanged")
+	flag.IntVar(&c.Episodes, "episodes", c.Episodes, "number of boards every network is scored on per generation")
+	flag.Var(&c.Offspring, "offspring", "mutated copies of the top networks, in rank order, as copies:rarity pairs")
+	flag.Parse()
+
+	in := neural.StaticLayer{
+		0, 0, 0,
+		0, 0, 0,
+		0, 0, 0,
+	}
+
+	seed := c.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	fmt.Println("seed:", seed)
+
+	t := neural.NewTrainer(seed)
+	t.Input = in
+	t.PopulationSize = c.Population
+	t.EliteCount = c.Elites
+	t.Episodes = c.Episodes
+	t.Offspring = c.Offspring
+	t.NewNetwork = func(r *rand.Rand) *neural.InferredLayer {
+		var l neural.Layer = in
+		for i := 0; i < c.Layers; i++ {
+			l = neural.NewFullyConnectedLayerWithRand(r, l, c.Width)
+		}
+		return neural.NewFullyConnectedLayerWithRand(r, l, len(in))
+	}
+	t.Environment = neural.TicTacToe{}
+
+	t.OnGeneration = func(gen int, best, avg, worst int, bestNet neural.Layer) {
+		fmt.Printf("[%10d]\n", best)
+	}
+	// Render the board the best network produced whenever a different network takes the lead.
+	t.OnBest = func(gen int, net neural.Layer, output []byte) {
+		for i, v := range output {
+			fmt.Printf(" %3d", v)
+			if i%3 == 2 {
+				fmt.Println()
+			}
+		}
+	}
+
+	// Run until interrupted.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	t.Run(ctx)
+}
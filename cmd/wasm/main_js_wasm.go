@@ -0,0 +1,54 @@
+// Command wasm exposes the evaluation of a network to JavaScript, to show that evolved networks can be run in the
+// browser. Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o neural.wasm ./cmd/wasm
+//
+// and load it with the wasm_exec.js support file that comes with Go. It defines two global functions:
+// neuralLoad(json), which loads a network written with neural.ToJSON, and neuralInfer(input), which takes and returns
+// an array of byte values. On failure, both return an Error instead of throwing it, since a panic in a function called
+// from JavaScript would stop the program.
+package main
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/blixt/neural"
+)
+
+func main() {
+	var net neural.Layer
+	js.Global().Set("neuralLoad", js.FuncOf(func(this js.Value, args []js.Value) any {
+		l, err := neural.FromJSON([]byte(args[0].String()))
+		if err != nil {
+			return jsError(err)
+		}
+		net = l
+		return nil
+	}))
+	js.Global().Set("neuralInfer", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if net == nil {
+			return jsError(errors.New("no network has been loaded"))
+		}
+		input := make([]byte, args[0].Length())
+		for i := range input {
+			input[i] = byte(args[0].Index(i).Int())
+		}
+		output, err := neural.Infer(net, input)
+		if err != nil {
+			return jsError(err)
+		}
+		values := make([]any, len(output))
+		for i, v := range output {
+			values[i] = int(v)
+		}
+		return js.ValueOf(values)
+	}))
+	// Keep the functions available to JavaScript.
+	select {}
+}
+
+// Returns err as a JavaScript Error.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
@@ -0,0 +1,188 @@
+package neural
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+)
+
+// Writes the source of a Go file in package pkg with a function named funcName that computes the same values as
+// l.GetValues() for the given input, so that an evolved network can be used without this package. The function takes
+// the values of the network's input layer, which must be its only StaticLayer, and returns a new slice with the output.
+// The edges are inlined as constants and intermediate values live in arrays on the stack, so the only allocation is the
+// result. Recurrent layers aren't supported since the function doesn't keep any state.
+func GenerateGo(l Layer, pkg, funcName string, w io.Writer) error {
+	in, err := inputOf(l)
+	if err != nil {
+		return err
+	}
+	g := &goWriter{names: map[any]string{layerKey(in): "in"}}
+	for _, layer := range Layers(l) {
+		if err := g.layer(layer); err != nil {
+			return err
+		}
+	}
+	// The header is written last since the imports depend on the layers.
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by neural.GenerateGo. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if g.bits {
+		b.WriteString("import \"math/bits\"\n\n")
+	}
+	fmt.Fprintf(&b, "// Computes the output of the network for the given input, which must be %d bytes long.\n", len(in))
+	fmt.Fprintf(&b, "func %s(in []byte) []byte {\n", funcName)
+	fmt.Fprintf(&b, "if len(in) != %d {\npanic(\"input must be %d bytes\")\n}\n", len(in), len(in))
+	b.Write(g.b.Bytes())
+	fmt.Fprintf(&b, "out := make([]byte, %d)\ncopy(out, %s[:])\nreturn out\n}\n", l.Size(), g.name(l))
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type goWriter struct {
+	b bytes.Buffer
+	n int
+	// The variable holding the values of each layer that has been written.
+	names map[any]string
+	// Whether the code uses the math/bits package.
+	bits bool
+}
+
+// Writes the statements computing a layer's values, which must come after those of the layers it reads from.
+func (g *goWriter) layer(l Layer) error {
+	key := layerKey(l)
+	if _, ok := g.names[key]; ok {
+		return nil
+	}
+	var name string
+	switch l := l.(type) {
+	case *InferredLayer:
+		left := g.name(l.Left)
+		name = g.declare(len(l.Nodes))
+		for i, node := range l.Nodes {
+			g.node(name, i, node, left)
+		}
+	case *MaxPoolLayer:
+		left := g.name(l.Left)
+		name = g.declare(l.Size())
+		for i, size := 0, l.Left.Size(); i < size; i += l.Window {
+			fmt.Fprintf(&g.b, "%s[%d] = %s[%d]", name, i/l.Window, left, i)
+			for j := i + 1; j < min(i+l.Window, size); j++ {
+				fmt.Fprintf(&g.b, " | %s[%d]", left, j)
+			}
+			g.b.WriteString("\n")
+		}
+	case *NotLayer:
+		left := g.name(l.Left)
+		name = g.declare(l.Size())
+		for i := 0; i < l.Size(); i++ {
+			fmt.Fprintf(&g.b, "%s[%d] = ^%s[%d]\n", name, i, left, i)
+		}
+	case *ShiftLayer:
+		left := g.name(l.Left)
+		name = g.declare(l.Size())
+		if n := l.Size(); n > 0 {
+			k := l.offset(n)
+			fmt.Fprintf(&g.b, "copy(%s[:], %s[%d:])\ncopy(%s[%d:], %s[:%d])\n", name, left, k, name, n-k, left, k)
+		}
+	case *LUTLayer:
+		left := g.name(l.Left)
+		name = g.declare(l.Size())
+		for i, n := range l.Nodes {
+			// Only the entries that can be indexed are included.
+			fmt.Fprintf(&g.b, "%s[%d] = [...]byte{", name, i)
+			for j, v := range n.Table[:1<<len(n.Inputs)] {
+				if j > 0 {
+					g.b.WriteString(", ")
+				}
+				fmt.Fprintf(&g.b, "0x%02x", v)
+			}
+			g.b.WriteString("}[0")
+			for j, in := range n.Inputs {
+				fmt.Fprintf(&g.b, "|%s[%d]>>%d&1<<%d", left, in.Index, in.Bit, j)
+			}
+			g.b.WriteString("]\n")
+		}
+	case *ConcatLayer:
+		name = g.declare(l.Size())
+		offset := 0
+		for _, c := range l.Layers {
+			fmt.Fprintf(&g.b, "copy(%s[%d:], %s[:])\n", name, offset, g.name(c))
+			offset += c.Size()
+		}
+	case *DAGLayer:
+		// The left values are gathered into one array first so that edge indices can be used as is.
+		in := g.declare(l.inSize())
+		offset := 0
+		for _, left := range l.Lefts {
+			fmt.Fprintf(&g.b, "copy(%s[%d:], %s[:])\n", in, offset, g.name(left))
+			offset += left.Size()
+		}
+		name = g.declare(len(l.Nodes))
+		for i, node := range l.Nodes {
+			g.node(name, i, node, in)
+		}
+	default:
+		return fmt.Errorf("cannot generate code for layer of type %T", l)
+	}
+	g.names[key] = name
+	return nil
+}
+
+// Returns the variable holding the values of a layer that has been written.
+func (g *goWriter) name(l Layer) string {
+	return g.names[layerKey(unwrap(l))]
+}
+
+// Declares an array variable for the values of a layer and returns its name.
+func (g *goWriter) declare(size int) string {
+	name := fmt.Sprintf("v%d", g.n)
+	g.n++
+	fmt.Fprintf(&g.b, "var %s [%d]byte\n", name, size)
+	return name
+}
+
+// Writes the statements computing node i of the variable name from the variable left.
+func (g *goWriter) node(name string, i int, node Node, left string) {
+	expr := nodeExpr(node, left)
+	if node.Max != 0 {
+		expr = fmt.Sprintf("min(%s, 0x%02x)", expr, node.Max)
+	}
+	if node.Activation != ActivationThreshold {
+		fmt.Fprintf(&g.b, "%s[%d] = %s\n", name, i, expr)
+		return
+	}
+	g.bits = true
+	fmt.Fprintf(&g.b, "if bits.OnesCount8(%s) > %d {\n%s[%d] = 1\n}\n", expr, node.Threshold, name, i)
+}
+
+// Returns an expression computing the accumulated value of a node that reads from the variable left. Since every edge's Xor and
+// the bias are XORed into the result regardless of the input, they're folded into a single constant.
+func nodeExpr(node Node, left string) string {
+	c := node.Bias
+	var terms []string
+	for _, e := range node.Inputs {
+		if k, ok := e.constant(); ok {
+			c ^= k
+			continue
+		}
+		c ^= e.Xor
+		switch e.Op {
+		case OpOr:
+			terms = append(terms, fmt.Sprintf("(%s[%d] | 0x%02x)", left, e.Index, e.And))
+		case OpNand:
+			c ^= 0xff
+			fallthrough
+		default:
+			terms = append(terms, fmt.Sprintf("%s[%d]&0x%02x", left, e.Index, e.And))
+		}
+	}
+	expr := fmt.Sprintf("0x%02x", c)
+	for _, t := range terms {
+		expr += " ^ " + t
+	}
+	return expr
+}
@@ -0,0 +1,74 @@
+package neural
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Compiles the code generated for a network that uses every supported layer type and checks that it computes the same
+// values as GetValues.
+func TestGenerateGo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles a program")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	r := rand.New(rand.NewSource(1))
+	in := make(StaticLayer, 6)
+	hidden := NewFullyConnectedLayerWithRand(r, NewConcatLayer(in, &NotLayer{Left: in}, &ShiftLayer{Left: in, Shift: 2}), 8)
+	for i := range hidden.Nodes {
+		hidden.Nodes[i].Activation = Activation(i % 2)
+		hidden.Nodes[i].Threshold = byte(i % 5)
+		hidden.Nodes[i].Max = byte(i * 40)
+	}
+	pool, err := NewMaxPoolLayer(hidden, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lut, err := NewLUTLayerWithRand(r, hidden, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewFullyConnectedLayerWithRand(r, NewDAGLayerWithRand(r, 5, pool, lut, in), 4)
+
+	dir := t.TempDir()
+	var src bytes.Buffer
+	if err := GenerateGo(l, "main", "network", &src); err != nil {
+		t.Fatal(err)
+	}
+	// The program prints the output for each input, one per line in hex.
+	var inputs, want strings.Builder
+	for k := 0; k < 20; k++ {
+		r.Read(in)
+		fmt.Fprintf(&inputs, "%#v,\n", []byte(in))
+		fmt.Fprintf(&want, "%x\n", l.GetValues())
+	}
+	prog := fmt.Sprintf("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfor _, in := range [][]byte{\n%s} {\n\t\tfmt.Printf(\"%%x\\n\", network(in))\n\t}\n}\n", inputs.String())
+	files := map[string]string{
+		"go.mod":     "module generated\n\ngo 1.21\n",
+		"network.go": src.String(),
+		"main.go":    prog,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if string(out) != want.String() {
+		t.Errorf("generated code output:\n%s\nwant:\n%s", out, want.String())
+	}
+}
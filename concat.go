@@ -0,0 +1,51 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// A layer that presents the values of several layers, in order, as a single layer.
+type ConcatLayer struct {
+	Layers []Layer
+}
+
+func NewConcatLayer(layers ...Layer) *ConcatLayer {
+	return &ConcatLayer{Layers: layers}
+}
+
+// Copies the layer and everything below it. Layers that several of the concatenated layers read from stay shared in
+// the copy, like with DAGLayer.Copy.
+func (l *ConcatLayer) Copy() Layer {
+	return copyShared(l, make(map[any]Layer))
+}
+
+func (l *ConcatLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *ConcatLayer) valuesInto(dst []byte) {
+	n := 0
+	for _, c := range l.Layers {
+		cv, buf := leftValues(c)
+		n += copy(dst[n:], cv)
+		putBuffer(buf)
+	}
+}
+
+// Mutates every trainable layer that is concatenated.
+func (l *ConcatLayer) Mutate(r *rand.Rand, rarity int) {
+	for _, c := range l.Layers {
+		mutateLeft(r, c, rarity)
+	}
+}
+
+func (l *ConcatLayer) Size() int {
+	var size int
+	for _, c := range l.Layers {
+		size += c.Size()
+	}
+	return size
+}
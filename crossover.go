@@ -0,0 +1,93 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// Creates a child network by taking every edge and bias from either a or b at random. The parents must have the same topology
+// (the same depth, layer sizes and number of edges per node), otherwise Crossover panics. Anything below the lowest
+// inferred layer (i.e., the input) is copied from a, and so are the edges and biases of layers that are frozen in a.
+func Crossover(r *rand.Rand, a, b *InferredLayer) *InferredLayer {
+	if len(a.Nodes) != len(b.Nodes) {
+		panic("crossover: parents have different layer sizes")
+	}
+	var nodes []Node
+	if a.Frozen {
+		nodes = copyNodes(a.Nodes)
+	} else {
+		nodes = crossoverNodes(r, a.Nodes, b.Nodes)
+	}
+	al, aok := a.Left.(*InferredLayer)
+	bl, bok := b.Left.(*InferredLayer)
+	var left Layer
+	switch {
+	case aok && bok:
+		left = Crossover(r, al, bl)
+	case aok || bok:
+		panic("crossover: parents have different depths")
+	default:
+		if a.Left.Size() != b.Left.Size() {
+			panic("crossover: parents have different input sizes")
+		}
+		left = a.Left.Copy()
+	}
+	return &InferredLayer{Nodes: nodes, Left: left, Frozen: a.Frozen}
+}
+
+// Takes every edge and bias from either a or b at random.
+func crossoverNodes(r *rand.Rand, a, b []Node) []Node {
+	nodes := make([]Node, len(a))
+	for i := range a {
+		ai, bi := a[i].Inputs, b[i].Inputs
+		if len(ai) != len(bi) {
+			panic("crossover: parents have different edge counts")
+		}
+		inputs := make([]Edge, len(ai))
+		for j := range ai {
+			if r.Intn(2) == 0 {
+				inputs[j] = ai[j]
+			} else {
+				inputs[j] = bi[j]
+			}
+		}
+		// The bias, activation and saturation are taken from the same parent since they depend on each other.
+		nodes[i] = a[i]
+		if r.Intn(2) != 0 {
+			nodes[i] = b[i]
+		}
+		nodes[i].Inputs = inputs
+	}
+	return nodes
+}
+
+// Creates a child genome by taking every byte from either a or b at random. Panics unless the genomes have the same
+// length.
+func CrossoverUniform(a, b []byte, r *rand.Rand) []byte {
+	if len(a) != len(b) {
+		panic("crossover: genomes have different lengths")
+	}
+	child := make([]byte, len(a))
+	for i := range child {
+		if r.Intn(2) == 0 {
+			child[i] = a[i]
+		} else {
+			child[i] = b[i]
+		}
+	}
+	return child
+}
+
+// Creates a child genome by picking two random points and taking the bytes between them from b and the rest from a.
+// Panics unless the genomes have the same length.
+func CrossoverTwoPoint(a, b []byte, r *rand.Rand) []byte {
+	if len(a) != len(b) {
+		panic("crossover: genomes have different lengths")
+	}
+	i, j := r.Intn(len(a)+1), r.Intn(len(a)+1)
+	if i > j {
+		i, j = j, i
+	}
+	child := append([]byte(nil), a...)
+	copy(child[i:j], b[i:j])
+	return child
+}
@@ -0,0 +1,252 @@
+package neural
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Returned (or panicked with, from GetValues) when a layer reads from itself, directly or through other layers.
+var ErrCycle = errors.New("network has a cycle")
+
+// A layer whose nodes can read from several earlier layers rather than only the adjacent one, e.g. for skip or
+// residual connections. Edge indices refer to the values of Lefts laid end to end, so index i of Lefts[k] is
+// Offset(k)+i. The lefts can be any layers below this one, including layers that other lefts read from.
+//
+// On the first evaluation, the layers below are checked for cycles and put in an order where every layer comes after
+// the layers it reads from. Every evaluation then computes each of them exactly once, however many layers read from
+// it, so e.g. a RecurrentLayer that two lefts read from advances by one step. Call Invalidate after changing which
+// layers the layers below read from.
+type DAGLayer struct {
+	Nodes []Node
+	Lefts []Layer
+
+	// Scratch buffers holding the left values, concatenated and separately.
+	in    []byte
+	lefts [][]byte
+	// This layer and the layers below it in evaluation order, the positions in order of the layers that each of them
+	// reads from, and the values of each from the current evaluation. Nil until the first evaluation.
+	order  []Layer
+	inputs [][]int
+	values [][]byte
+}
+
+// Creates a layer where every node is connected to every node in all of the left layers, using r for the initial
+// edges.
+func NewDAGLayerWithRand(r *rand.Rand, size int, lefts ...Layer) *DAGLayer {
+	checkSize(size)
+	l := &DAGLayer{Nodes: make([]Node, size), Lefts: lefts}
+	inSize := l.inSize()
+	for i := range l.Nodes {
+		edges := make([]Edge, inSize)
+		for j := range edges {
+			edges[j] = randomEdge(r, j)
+		}
+		l.Nodes[i].Inputs = edges
+	}
+	return l
+}
+
+// Returns the edge index of the first value of Lefts[k].
+func (l *DAGLayer) Offset(k int) int {
+	offset := 0
+	for _, left := range l.Lefts[:k] {
+		offset += left.Size()
+	}
+	return offset
+}
+
+// Returns the total size of the left layers.
+func (l *DAGLayer) inSize() int {
+	return l.Offset(len(l.Lefts))
+}
+
+// Copies the layer and everything below it. Unlike copying each left layer separately, layers that several lefts read
+// from stay shared in the copy.
+func (l *DAGLayer) Copy() Layer {
+	return copyShared(l, make(map[any]Layer))
+}
+
+// Panics with ErrCycle if the layers below form a cycle, since evaluating them would never end. The check is done on the
+// first evaluation only.
+func (l *DAGLayer) GetValues() []byte {
+	v := make([]byte, len(l.Nodes))
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *DAGLayer) valuesInto(dst []byte) {
+	if l.order == nil {
+		if err := checkCycles(l); err != nil {
+			panic(err)
+		}
+		l.plan()
+	}
+	last := len(l.order) - 1
+	for i, layer := range l.order {
+		l.lefts = l.lefts[:0]
+		for _, j := range l.inputs[i] {
+			l.lefts = append(l.lefts, l.values[j])
+		}
+		if i == last {
+			l.values[i] = dst
+		}
+		l.values[i] = evaluateFrom(layer, l.lefts, l.values[i])
+	}
+	// Don't hold on to dst after returning.
+	l.values[last] = nil
+}
+
+// Discards the evaluation order of the layers below, so that it's worked out again on the next evaluation.
+func (l *DAGLayer) Invalidate() {
+	l.order, l.inputs, l.values, l.lefts = nil, nil, nil, nil
+}
+
+// Works out the evaluation order of this layer and the layers below it.
+func (l *DAGLayer) plan() {
+	l.order = Layers(l)
+	positions := make(map[any]int, len(l.order))
+	for i, layer := range l.order {
+		positions[layerKey(layer)] = i
+	}
+	l.inputs = make([][]int, len(l.order))
+	for i, layer := range l.order {
+		for _, left := range lefts(layer) {
+			l.inputs[i] = append(l.inputs[i], positions[layerKey(unwrap(left))])
+		}
+	}
+	l.values = make([][]byte, len(l.order))
+}
+
+// Returns the values of a layer computed from the values of the layers it reads from (see lefts), reusing buf if it's
+// large enough. Layers that this package doesn't know how to compute from their left values are evaluated with
+// GetValues, and the values of a StaticLayer are returned as is.
+func evaluateFrom(l Layer, in [][]byte, buf []byte) []byte {
+	switch l := l.(type) {
+	case StaticLayer:
+		return l
+	case *InferredLayer:
+		buf = resize(buf, len(l.Nodes))
+		computeNodes(l.Nodes, in[0], buf)
+	case *RecurrentLayer:
+		buf = resize(buf, len(l.Nodes))
+		l.step(in[0], buf)
+	case *MaxPoolLayer:
+		buf = resize(buf, (len(in[0])+l.Window-1)/l.Window)
+		clear(buf)
+		l.pool(in[0], buf)
+	case *NotLayer:
+		buf = resize(buf, len(in[0]))
+		complement(in[0], buf)
+	case *ShiftLayer:
+		buf = resize(buf, len(in[0]))
+		l.rotate(in[0], buf)
+	case *LUTLayer:
+		buf = resize(buf, len(l.Nodes))
+		l.lookup(in[0], buf)
+	case *ConcatLayer:
+		buf = buf[:0]
+		for _, v := range in {
+			buf = append(buf, v...)
+		}
+	case *DAGLayer:
+		l.in = l.in[:0]
+		for _, v := range in {
+			l.in = append(l.in, v...)
+		}
+		buf = resize(buf, len(l.Nodes))
+		computeNodes(l.Nodes, l.in, buf)
+	default:
+		buf = append(buf[:0], l.GetValues()...)
+	}
+	return buf
+}
+
+// Randomly flips bits in the edges of this layer and all layers below it, like InferredLayer.Mutate. A layer that
+// several lefts read from is mutated once for each of them.
+func (l *DAGLayer) Mutate(r *rand.Rand, rarity int) {
+	mutateWeights(r, l.Nodes, rarity)
+	for _, left := range l.Lefts {
+		mutateLeft(r, left, rarity)
+	}
+}
+
+func (l *DAGLayer) Size() int {
+	return len(l.Nodes)
+}
+
+// Returns an error wrapping ErrCycle if any layer of the network reads from itself.
+func checkCycles(l Layer) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[any]int)
+	var visit func(l Layer) error
+	visit = func(l Layer) error {
+		l = unwrap(l)
+		key := layerKey(l)
+		switch state[key] {
+		case visiting:
+			return fmt.Errorf("%w through a %T", ErrCycle, l)
+		case done:
+			return nil
+		}
+		state[key] = visiting
+		for _, left := range lefts(l) {
+			if err := visit(left); err != nil {
+				return err
+			}
+		}
+		state[key] = done
+		return nil
+	}
+	return visit(l)
+}
+
+// Copies a layer and everything below it, copying each layer once so that shared layers stay shared. Input layers are
+// shared with the original, like Copy does.
+func copyShared(l Layer, copies map[any]Layer) Layer {
+	key := layerKey(l)
+	if c, ok := copies[key]; ok {
+		return c
+	}
+	var c Layer
+	switch l := l.(type) {
+	case *InferredLayer:
+		c = &InferredLayer{Nodes: copyNodes(l.Nodes), Left: copyShared(l.Left, copies), Frozen: l.Frozen}
+	case *ScoredLayer:
+		c = &ScoredLayer{InferredLayer: copyShared(l.InferredLayer, copies).(*InferredLayer)}
+	case *RecurrentLayer:
+		c = &RecurrentLayer{
+			Nodes: copyNodes(l.Nodes),
+			Left:  copyShared(l.Left, copies),
+			State: append([]byte(nil), l.State...),
+		}
+	case *MaxPoolLayer:
+		c = &MaxPoolLayer{Left: copyShared(l.Left, copies), Window: l.Window}
+	case *NotLayer:
+		c = &NotLayer{Left: copyShared(l.Left, copies)}
+	case *ShiftLayer:
+		c = &ShiftLayer{Left: copyShared(l.Left, copies), Shift: l.Shift}
+	case *LUTLayer:
+		c = &LUTLayer{Nodes: copyLUTNodes(l.Nodes), Left: copyShared(l.Left, copies)}
+	case *ConcatLayer:
+		layers := make([]Layer, len(l.Layers))
+		for i, child := range l.Layers {
+			layers[i] = copyShared(child, copies)
+		}
+		c = &ConcatLayer{Layers: layers}
+	case *DAGLayer:
+		layers := make([]Layer, len(l.Lefts))
+		for i, left := range l.Lefts {
+			layers[i] = copyShared(left, copies)
+		}
+		c = &DAGLayer{Nodes: copyNodes(l.Nodes), Lefts: layers}
+	default:
+		c = l.Copy()
+	}
+	copies[key] = c
+	return c
+}
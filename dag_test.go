@@ -0,0 +1,67 @@
+package neural
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Builds a stack of DAG layers that each read from the input and every DAG layer below them, which would take
+// exponential time to evaluate, hash or compare if shared layers were visited once per path to them.
+func newDAGStack(r *rand.Rand, in StaticLayer, depth int) []*DAGLayer {
+	lefts := []Layer{in}
+	var stack []*DAGLayer
+	for i := 0; i < depth; i++ {
+		d := NewDAGLayerWithRand(r, 2, lefts...)
+		stack = append(stack, d)
+		lefts = append(lefts, d)
+	}
+	return stack
+}
+
+func TestDAGStack(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := StaticLayer{1, 2, 3}
+	stack := newDAGStack(r, in, 24)
+	top := stack[len(stack)-1]
+
+	// Each layer's values, worked out one layer at a time from the values of the layers below.
+	values := [][]byte{in}
+	for _, d := range stack {
+		var lv []byte
+		for _, v := range values {
+			lv = append(lv, v...)
+		}
+		v := make([]byte, len(d.Nodes))
+		computeNodes(d.Nodes, lv, v)
+		values = append(values, v)
+	}
+	if got, want := top.GetValues(), values[len(values)-1]; !bytes.Equal(got, want) {
+		t.Errorf("values %v, want %v", got, want)
+	}
+
+	c := top.Copy()
+	if !Equal(top, c) || Hash(top) != Hash(c) {
+		t.Error("copy of the stack isn't equal to it")
+	}
+	c.(*DAGLayer).Lefts[1].(*DAGLayer).Nodes[0].Bias ^= 1
+	if Equal(top, c) {
+		t.Error("changed copy of the stack is still equal to it")
+	}
+}
+
+func TestDAGSharedRecurrentLayer(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := StaticLayer{1, 2, 3}
+	rec := NewRecurrentLayerWithRand(r, in, 3)
+	want := rec.Copy().(*RecurrentLayer)
+	want.Left = in
+	d := NewDAGLayerWithRand(r, 2, rec, &NotLayer{Left: rec})
+	for i := 0; i < 3; i++ {
+		d.GetValues()
+		want.GetValues()
+		if !bytes.Equal(rec.State, want.State) {
+			t.Fatalf("evaluation %d: state %v, want %v after one step per evaluation", i, rec.State, want.State)
+		}
+	}
+}
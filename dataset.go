@@ -0,0 +1,117 @@
+package neural
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// An input and the output that a network should produce for it.
+type Example struct {
+	Input, Target []byte
+}
+
+// A set of labeled examples for supervised training.
+type Dataset []Example
+
+// Shuffles data using r and splits it into a training set holding trainFrac of the examples and a test set holding the
+// rest.
+func Split(data Dataset, trainFrac float64, r *rand.Rand) (train, test Dataset) {
+	n := int(trainFrac*float64(len(data)) + 0.5)
+	if n < 0 {
+		n = 0
+	} else if n > len(data) {
+		n = len(data)
+	}
+	shuffled := make(Dataset, len(data))
+	for i, j := range r.Perm(len(data)) {
+		shuffled[i] = data[j]
+	}
+	return shuffled[:n:n], shuffled[n:]
+}
+
+// Returns the sum of a network's HammingFitness scores over all the examples, by setting input to each example and
+// evaluating the network.
+func (d Dataset) Score(input StaticLayer, net Layer) (int, error) {
+	var score int
+	for _, ex := range d {
+		if err := input.Set(ex.Input); err != nil {
+			return 0, err
+		}
+		distance, err := HammingDistance(valuesOf(net), ex.Target)
+		if err != nil {
+			return 0, err
+		}
+		score -= distance
+	}
+	return score, nil
+}
+
+// Scores a network by how many bits of its output differ from the target of the current example, i.e., a perfect
+// output scores 0 and every wrong bit scores -1. Episode must be used as the trainer's Episode function so that each
+// episode presents the next example of the current batch.
+type HammingFitness struct {
+	Data Dataset
+	// The number of random examples in each batch. A new batch is sampled once all examples in the previous one have
+	// been presented. If BatchSize is 0 or at least the size of Data, every batch is the whole dataset in order.
+	BatchSize int
+
+	batch  []int
+	next   int
+	target []byte
+	// The error from setting the input of the current example, which Evaluate returns for every network.
+	err error
+}
+
+// Sets input to the input of the next example. If their sizes differ, input is left as it is and every network fails
+// the episode with the error.
+func (f *HammingFitness) Episode(r *rand.Rand, input StaticLayer) {
+	if f.next == 0 {
+		f.sample(r)
+	}
+	ex := f.Data[f.batch[f.next]]
+	f.next = (f.next + 1) % len(f.batch)
+	f.err = input.Set(ex.Input)
+	f.target = ex.Target
+}
+
+func (f *HammingFitness) Evaluate(r *rand.Rand, input StaticLayer, output []byte) (int, error) {
+	if f.err != nil {
+		return 0, fmt.Errorf("example input: %w", f.err)
+	}
+	distance, err := HammingDistance(output, f.target)
+	if err != nil {
+		return 0, err
+	}
+	return -distance, nil
+}
+
+// Returns the number of examples in a batch.
+func (f *HammingFitness) batchSize() int {
+	if f.BatchSize <= 0 || f.BatchSize >= len(f.Data) {
+		return len(f.Data)
+	}
+	return f.BatchSize
+}
+
+// Picks the examples for the next batch.
+func (f *HammingFitness) sample(r *rand.Rand) {
+	if f.batchSize() == len(f.Data) {
+		f.batch = f.batch[:0]
+		for i := range f.Data {
+			f.batch = append(f.batch, i)
+		}
+		return
+	}
+	f.batch = r.Perm(len(f.Data))[:f.batchSize()]
+}
+
+// Configures the trainer to score networks on a batch of batchSize random examples from data every generation, using
+// HammingFitness. If batchSize is 0 or at least the size of data, networks are scored on the whole dataset. The
+// examples' inputs must be the same size as the trainer's Input, or every network fails the episodes of those that
+// aren't.
+func (t *Trainer) Supervise(data Dataset, batchSize int) {
+	f := &HammingFitness{Data: data, BatchSize: batchSize}
+	t.Episode = f.Episode
+	t.Fitness = f
+	t.Episodes = f.batchSize()
+}
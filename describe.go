@@ -0,0 +1,76 @@
+package neural
+
+import (
+	"unsafe"
+)
+
+// A summary of a network's topology.
+type NetworkStats struct {
+	// The number of layers, including the input.
+	Layers int
+	// The number of nodes in each layer, from the input to the output.
+	LayerSizes []int
+	// The total number of edges, counting every input bit of a lookup table (see LUTLayer) as an edge.
+	Edges int
+	// The total number of trainable bytes, i.e., an And and a Xor mask per edge and the entries of every lookup table.
+	ParamBytes int
+	// An estimate of the memory used by the network, including slice headers and cached values.
+	MemBytes int
+}
+
+// Returns the total number of edges in a network.
+func ParamCount(l Layer) int {
+	return Describe(l).Edges
+}
+
+// Estimates the number of bytes of memory used by a network.
+func MemBytes(l Layer) int {
+	return Describe(l).MemBytes
+}
+
+// Summarizes the topology of a network. Layers that are read by more than one layer (e.g., a shared input) are only
+// counted once.
+func Describe(l Layer) NetworkStats {
+	var s NetworkStats
+	for _, l := range Layers(l) {
+		var nodes []Node
+		switch l := l.(type) {
+		case *InferredLayer:
+			nodes = l.Nodes
+			// The struct itself, plus the cached values and the left values they were computed from.
+			s.MemBytes += int(unsafe.Sizeof(*l)) + cap(l.cache) + cap(l.cacheLeft)
+		case *RecurrentLayer:
+			nodes = l.Nodes
+			s.MemBytes += int(unsafe.Sizeof(*l)) + cap(l.State) + cap(l.in)
+		case *MaxPoolLayer:
+			s.MemBytes += int(unsafe.Sizeof(*l))
+		case *NotLayer:
+			s.MemBytes += int(unsafe.Sizeof(*l))
+		case *ShiftLayer:
+			s.MemBytes += int(unsafe.Sizeof(*l))
+		case *LUTLayer:
+			s.MemBytes += int(unsafe.Sizeof(*l)) + len(l.Nodes)*int(unsafe.Sizeof(LUTNode{}))
+			for _, n := range l.Nodes {
+				s.Edges += len(n.Inputs)
+				s.ParamBytes += len(n.Table) - 2*len(n.Inputs)
+				s.MemBytes += cap(n.Inputs) * int(unsafe.Sizeof(BitInput{}))
+			}
+		case *ConcatLayer:
+			s.MemBytes += int(unsafe.Sizeof(*l)) + len(l.Layers)*int(unsafe.Sizeof(l.Layers[0]))
+		case *DAGLayer:
+			nodes = l.Nodes
+			s.MemBytes += int(unsafe.Sizeof(*l)) + len(l.Lefts)*int(unsafe.Sizeof(l.Lefts[0])) + cap(l.in)
+		case StaticLayer:
+			s.MemBytes += int(unsafe.Sizeof(l)) + cap(l)
+		}
+		s.MemBytes += len(nodes) * int(unsafe.Sizeof(Node{}))
+		for _, n := range nodes {
+			s.Edges += len(n.Inputs)
+			s.MemBytes += cap(n.Inputs) * int(unsafe.Sizeof(Edge{}))
+		}
+		s.Layers++
+		s.LayerSizes = append(s.LayerSizes, l.Size())
+	}
+	s.ParamBytes += 2 * s.Edges
+	return s
+}
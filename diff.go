@@ -0,0 +1,55 @@
+package neural
+
+import (
+	"fmt"
+)
+
+// A difference between two networks with the same shape.
+type EdgeDiff struct {
+	// The layer, counting from 0 at the output, and the node and edge within it. Edge is -1 if the difference is in the
+	// node's bias rather than in one of its edges.
+	Depth, Node, Edge int
+	// The edge in the first and second network.
+	Old, New Edge
+	// The node's bias in the first and second network, if Edge is -1.
+	OldBias, NewBias byte
+}
+
+func (d EdgeDiff) String() string {
+	if d.Edge < 0 {
+		return fmt.Sprintf("layer %d, node %d: bias %02x -> %02x", d.Depth, d.Node, d.OldBias, d.NewBias)
+	}
+	return fmt.Sprintf("layer %d, node %d, edge %d: %v -> %v", d.Depth, d.Node, d.Edge, d.Old, d.New)
+}
+
+// Lists every edge and bias that differs between two chains of inferred layers, e.g. a mutant and its parent, from the
+// output layer down. The chains must have the same shape (depth, layer sizes and number of edges per node), otherwise
+// an error is returned. What the chains read from isn't compared.
+func Diff(a, b *InferredLayer) ([]EdgeDiff, error) {
+	ca, cb := inferredChain(a), inferredChain(b)
+	if len(ca) != len(cb) {
+		return nil, fmt.Errorf("networks have different depths: %d and %d", len(ca), len(cb))
+	}
+	var diffs []EdgeDiff
+	for depth := 0; depth < len(ca); depth++ {
+		la, lb := ca[len(ca)-1-depth], cb[len(cb)-1-depth]
+		if len(la.Nodes) != len(lb.Nodes) {
+			return nil, fmt.Errorf("layer %d: different sizes: %d and %d", depth, len(la.Nodes), len(lb.Nodes))
+		}
+		for i := range la.Nodes {
+			na, nb := la.Nodes[i], lb.Nodes[i]
+			if len(na.Inputs) != len(nb.Inputs) {
+				return nil, fmt.Errorf("layer %d, node %d: different edge counts: %d and %d", depth, i, len(na.Inputs), len(nb.Inputs))
+			}
+			if na.Bias != nb.Bias {
+				diffs = append(diffs, EdgeDiff{Depth: depth, Node: i, Edge: -1, OldBias: na.Bias, NewBias: nb.Bias})
+			}
+			for j := range na.Inputs {
+				if na.Inputs[j] != nb.Inputs[j] {
+					diffs = append(diffs, EdgeDiff{Depth: depth, Node: i, Edge: j, Old: na.Inputs[j], New: nb.Inputs[j]})
+				}
+			}
+		}
+	}
+	return diffs, nil
+}
@@ -0,0 +1,26 @@
+package neural
+
+// Measures how different the networks in a population are, as the average over all pairs of networks of the fraction
+// of genome bits (see Genome) that differ between them. Pairs with differently sized genomes count as entirely
+// different. The result is 0 for a population of identical networks and around 0.5 for random networks.
+func Diversity(pop []ScoredLayer) float64 {
+	if len(pop) < 2 {
+		return 0
+	}
+	genomes := make([][]byte, len(pop))
+	for i, p := range pop {
+		genomes[i] = Genome(p.InferredLayer)
+	}
+	var sum float64
+	for i := range genomes {
+		for j := i + 1; j < len(genomes); j++ {
+			distance, err := HammingDistance(genomes[i], genomes[j])
+			if err != nil {
+				sum++
+			} else if len(genomes[i]) > 0 {
+				sum += float64(distance) / float64(8*len(genomes[i]))
+			}
+		}
+	}
+	return sum / float64(len(pop)*(len(pop)-1)/2)
+}
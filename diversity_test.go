@@ -0,0 +1,28 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDiversity(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := make(StaticLayer, 9)
+	newNetwork := func() *InferredLayer {
+		return NewFullyConnectedLayerWithRand(r, NewFullyConnectedLayerWithRand(r, in, 9), 9)
+	}
+	l := newNetwork()
+	identical := make([]ScoredLayer, 20)
+	random := make([]ScoredLayer, 20)
+	for i := range identical {
+		identical[i].InferredLayer = l.CopyInferred()
+		random[i].InferredLayer = newNetwork()
+	}
+	if d := Diversity(identical); d != 0 {
+		t.Errorf("identical networks have diversity %v, want 0", d)
+	}
+	if d := Diversity(random); math.Abs(d-0.5) > 0.05 {
+		t.Errorf("random networks have diversity %v, want about 0.5", d)
+	}
+}
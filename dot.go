@@ -0,0 +1,139 @@
+package neural
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Writes the network as a Graphviz graph, with one rank per layer and the input on the left. Edges of inferred layers
+// are labeled with their operation and masks in hex. Render it with e.g. `dot -Tpng`.
+func ToDOT(l Layer, w io.Writer) error {
+	var b bytes.Buffer
+	b.WriteString("digraph network {\n\trankdir=LR;\n\tnode [shape=circle];\n")
+	d := &dotWriter{b: &b, names: make(map[any]string)}
+	if _, err := d.layer(l); err != nil {
+		return err
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+type dotWriter struct {
+	b *bytes.Buffer
+	n int
+	// The names of layers that have already been written, so that shared layers (e.g., the input) appear once.
+	names map[any]string
+}
+
+// Writes a layer and everything to the left of it, returning the name that its nodes are prefixed with.
+func (d *dotWriter) layer(l Layer) (string, error) {
+	l = unwrap(l)
+	key := layerKey(l)
+	if name, ok := d.names[key]; ok {
+		return name, nil
+	}
+
+	// Layers to the left are written first so that they get lower names.
+	var leftNames []string
+	if _, ok := l.(StaticLayer); !ok {
+		ls := lefts(l)
+		if ls == nil {
+			return "", fmt.Errorf("cannot render layer of type %T", l)
+		}
+		for _, left := range ls {
+			name, err := d.layer(left)
+			if err != nil {
+				return "", err
+			}
+			leftNames = append(leftNames, name)
+		}
+	}
+
+	name := fmt.Sprintf("l%d", d.n)
+	d.n++
+	d.names[key] = name
+	fmt.Fprintf(d.b, "\tsubgraph %s {\n\t\trank=same;\n", name)
+	for i := 0; i < l.Size(); i++ {
+		if s, ok := l.(StaticLayer); ok {
+			fmt.Fprintf(d.b, "\t\t%s_%d [shape=box, label=\"%d\"];\n", name, i, s[i])
+		} else if b := dotBias(l, i); b != 0 {
+			fmt.Fprintf(d.b, "\t\t%s_%d [label=\"%d ^%02x\"];\n", name, i, i, b)
+		} else {
+			fmt.Fprintf(d.b, "\t\t%s_%d [label=\"%d\"];\n", name, i, i)
+		}
+	}
+	d.b.WriteString("\t}\n")
+
+	switch l := l.(type) {
+	case *InferredLayer:
+		d.edges(leftNames[0], name, l.Nodes, l.Left.Size())
+	case *RecurrentLayer:
+		d.edges(leftNames[0], name, l.Nodes, l.Left.Size())
+	case *MaxPoolLayer:
+		for i := 0; i < l.Left.Size(); i++ {
+			fmt.Fprintf(d.b, "\t%s_%d -> %s_%d;\n", leftNames[0], i, name, i/l.Window)
+		}
+	case *NotLayer:
+		for i := 0; i < l.Size(); i++ {
+			fmt.Fprintf(d.b, "\t%s_%d -> %s_%d [label=\"~\"];\n", leftNames[0], i, name, i)
+		}
+	case *ShiftLayer:
+		for i, n := 0, l.Size(); i < n; i++ {
+			fmt.Fprintf(d.b, "\t%s_%d -> %s_%d;\n", leftNames[0], (i+l.offset(n))%n, name, i)
+		}
+	case *LUTLayer:
+		for i, n := range l.Nodes {
+			for _, in := range n.Inputs {
+				fmt.Fprintf(d.b, "\t%s_%d -> %s_%d [label=\"bit %d\"];\n", leftNames[0], in.Index, name, i, in.Bit)
+			}
+		}
+	case *ConcatLayer:
+		var offset int
+		for c, child := range l.Layers {
+			for i := 0; i < child.Size(); i++ {
+				fmt.Fprintf(d.b, "\t%s_%d -> %s_%d;\n", leftNames[c], i, name, offset+i)
+			}
+			offset += child.Size()
+		}
+	case *DAGLayer:
+		for i, n := range l.Nodes {
+			for _, e := range n.Inputs {
+				k, index := 0, e.Index
+				for k < len(l.Lefts)-1 && index >= l.Lefts[k].Size() {
+					index -= l.Lefts[k].Size()
+					k++
+				}
+				fmt.Fprintf(d.b, "\t%s_%d -> %s_%d [label=\"%s\"];\n", leftNames[k], index, name, i, e.masks())
+			}
+		}
+	}
+	return name, nil
+}
+
+// Writes the edges of the nodes in layer name. Indices from leftSize and up refer to the layer's own previous output.
+func (d *dotWriter) edges(left, name string, nodes []Node, leftSize int) {
+	for i, n := range nodes {
+		for _, e := range n.Inputs {
+			if e.Index < leftSize {
+				fmt.Fprintf(d.b, "\t%s_%d -> %s_%d [label=\"%s\"];\n", left, e.Index, name, i, e.masks())
+			} else {
+				fmt.Fprintf(d.b, "\t%s_%d -> %s_%d [label=\"%s\", style=dashed, constraint=false];\n", name, e.Index-leftSize, name, i, e.masks())
+			}
+		}
+	}
+}
+
+// Returns the bias of node i of a layer, or 0 if the layer has no biases.
+func dotBias(l Layer, i int) byte {
+	switch l := l.(type) {
+	case *InferredLayer:
+		return l.Nodes[i].Bias
+	case *RecurrentLayer:
+		return l.Nodes[i].Bias
+	case *DAGLayer:
+		return l.Nodes[i].Bias
+	}
+	return 0
+}
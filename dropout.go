@@ -0,0 +1,31 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// Writes the values of l into dst like GetValuesInto, except that every node of the chain of inferred layers below l
+// outputs 0 with a probability of p, using r. The nodes of l itself are never dropped out since they're the output, and
+// layers below the chain are evaluated as usual. The caches of the layers in the chain are neither used nor updated.
+func dropoutValues(r *rand.Rand, l *InferredLayer, p float64, dst []byte) {
+	lv, buf := dropoutLeft(r, l, p)
+	defer putBuffer(buf)
+	computeNodes(l.Nodes, lv, dst)
+}
+
+// Like leftValues, but with dropout applied to the left layer if it's an inferred layer.
+func dropoutLeft(r *rand.Rand, l *InferredLayer, p float64) ([]byte, *[]byte) {
+	left, ok := l.Left.(*InferredLayer)
+	if !ok {
+		return leftValues(l.Left)
+	}
+	buf := getBuffer(left.Size())
+	v := *buf
+	dropoutValues(r, left, p, v)
+	for i := range v {
+		if r.Float64() < p {
+			v[i] = 0
+		}
+	}
+	return v, buf
+}
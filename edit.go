@@ -0,0 +1,93 @@
+package neural
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Adds a fully connected layer of the given size on top of net, which can be any layer, and returns it as the new
+// output layer. The initial edges come from the global random source; use NewFullyConnectedLayerWithRand for
+// reproducible networks.
+func AppendLayer(net Layer, size int) *InferredLayer {
+	return NewFullyConnectedLayer(net, size)
+}
+
+// Splices a new fully connected layer of the given size into a network so that it ends up at depth, counting from 0 at
+// the output layer, and returns the network's new output layer. At depth 0 the new layer is placed on top of net and
+// returned. Otherwise it's placed below the inferred layer at depth-1, which is changed to read from it, and net is
+// returned. Depth can be at most the number of inferred layers in the chain, which places the new layer right above
+// whatever the lowest one reads from, e.g. the input. The edges of the layer that now reads from the new layer are
+// kept, so size must be large enough for all of them. The initial edges come from the global random source.
+func InsertLayer(net *InferredLayer, depth, size int) (*InferredLayer, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("layer size %d is negative", size)
+	}
+	if depth == 0 {
+		return AppendLayer(net, size), nil
+	}
+	chain := inferredChain(net)
+	if depth < 0 || depth > len(chain) {
+		return nil, fmt.Errorf("depth %d is out of range [0, %d]", depth, len(chain))
+	}
+	consumer := chain[len(chain)-depth]
+	for _, n := range consumer.Nodes {
+		for _, e := range n.Inputs {
+			if e.Index >= size {
+				return nil, fmt.Errorf("layer %d has an edge to node %d, which doesn't fit in a layer of size %d", depth-1, e.Index, size)
+			}
+		}
+	}
+	consumer.Left = NewFullyConnectedLayer(consumer.Left, size)
+	consumer.Invalidate()
+	return net, nil
+}
+
+// Removes the inferred layer at depth from a network, counting from 0 at the output layer, and returns the network's
+// new output layer. At depth 0 that's the layer below net, which must be an inferred layer too. Otherwise the layer
+// reading from the removed one is fused with it (see Fuse), so that it reads from the removed layer's left layer
+// instead while computing the same values, and net is returned. Since activations and saturation don't compose, layers
+// whose nodes have either can only be removed at depth 0.
+func RemoveLayer(net *InferredLayer, depth int) (*InferredLayer, error) {
+	chain := inferredChain(net)
+	if depth < 0 || depth >= len(chain) {
+		return nil, fmt.Errorf("depth %d is out of range [0, %d)", depth, len(chain))
+	}
+	if depth == 0 {
+		left, ok := net.Left.(*InferredLayer)
+		if !ok {
+			return nil, errors.New("cannot remove the only inferred layer")
+		}
+		return left, nil
+	}
+	consumer := chain[len(chain)-depth]
+	fused := Fuse(consumer)
+	if fused == consumer {
+		return nil, fmt.Errorf("layer %d has nodes with an activation or saturation and cannot be fused away", depth)
+	}
+	consumer.Nodes, consumer.Left = fused.Nodes, fused.Left
+	consumer.Invalidate()
+	return net, nil
+}
+
+// Returns a deep copy (see DeepCopy) of the layer at depth in a network, counting from 0 at the output layer, together
+// with everything it reads from, e.g. so that the lower layers of an evolved network can be used as a feature
+// extractor under a new output layer. Depths can only be counted through layers that read from a single layer, so
+// there must be no ConcatLayer above depth.
+func Subnetwork(l Layer, depth int) (Layer, error) {
+	if depth < 0 {
+		return nil, fmt.Errorf("depth %d is negative", depth)
+	}
+	l = unwrap(l)
+	for d := 0; d < depth; d++ {
+		ls := lefts(l)
+		switch len(ls) {
+		case 0:
+			return nil, fmt.Errorf("depth %d is out of range for a network of depth %d", depth, d+1)
+		case 1:
+			l = unwrap(ls[0])
+		default:
+			return nil, fmt.Errorf("layer %d reads from %d layers", d, len(ls))
+		}
+	}
+	return DeepCopy(l), nil
+}
@@ -0,0 +1,31 @@
+package neural
+
+import (
+	"math"
+)
+
+// Evaluates a network for each of the probe inputs (see GetValuesBatch) and returns the Shannon entropy in bits of
+// each output value across them, from 0 for an output that is the same for every input to 8 for one that takes all
+// 256 values equally often. Outputs with an entropy near 0 are dead: they don't depend on the input and contribute
+// nothing to the layers reading from them. Use Subnetwork to probe a hidden layer.
+func OutputEntropy(l Layer, inputs [][]byte) ([]float64, error) {
+	outputs, err := GetValuesBatch(l, inputs)
+	if err != nil {
+		return nil, err
+	}
+	entropy := make([]float64, l.Size())
+	var counts [256]int
+	for i := range entropy {
+		clear(counts[:])
+		for _, output := range outputs {
+			counts[output[i]]++
+		}
+		for _, c := range counts {
+			if c > 0 {
+				p := float64(c) / float64(len(outputs))
+				entropy[i] -= p * math.Log2(p)
+			}
+		}
+	}
+	return entropy, nil
+}
@@ -0,0 +1,14 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// A task that networks are trained on, combining what a trainer's Episode and Fitness do separately: Reset produces
+// the input for the next episode and Score scores a network's output for it. Reset is called from one goroutine at a
+// time, while Score is called concurrently like Fitness.Evaluate, so it must not depend on state changed by Reset
+// other than through input. Randomness should come from r.
+type Environment interface {
+	Reset(r *rand.Rand) []byte
+	Score(r *rand.Rand, input, output []byte) (int, error)
+}
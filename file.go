@@ -0,0 +1,91 @@
+package neural
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Writes a layer to the file at path. The file is replaced atomically so an existing file is never left half-written.
+func SaveToFile(path string, l Layer) error {
+	return writeFile(path, func(w io.Writer) error {
+		return EncodeLayer(w, l)
+	})
+}
+
+// Like SaveToFile, but compresses the file with gzip. LoadFromFile reads both kinds of files.
+func SaveCompressed(path string, l Layer) error {
+	return writeFile(path, gzipped(func(w io.Writer) error {
+		return EncodeLayer(w, l)
+	}))
+}
+
+// Wraps encode so that what it writes is compressed with gzip.
+func gzipped(encode func(w io.Writer) error) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		if err := encode(zw); err != nil {
+			return err
+		}
+		return zw.Close()
+	}
+}
+
+// Atomically replaces the file at path with what encode writes.
+func writeFile(path string, encode func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	w := bufio.NewWriter(f)
+	if err := encode(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Reads a layer previously written with SaveToFile or SaveCompressed.
+func LoadFromFile(path string) (Layer, error) {
+	var l Layer
+	err := readFile(path, func(r io.Reader) error {
+		var err error
+		l, err = DecodeLayer(r)
+		return err
+	})
+	return l, err
+}
+
+// Calls decode with the contents of the file at path, which are decompressed first if they start with the gzip magic
+// bytes. Uncompressed files never start with them since they start with the format header or, for version 1, with a gob
+// message length, which is either below 0x80 and followed by a byte that isn't 0x8b, or at least 0xf8.
+func readFile(path string, decode func(r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		return decode(zr)
+	}
+	return decode(br)
+}
@@ -0,0 +1,36 @@
+package neural
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveToFileRoundTrip(t *testing.T) {
+	tr := newTestTrainer(1)
+	for i := 0; i < 3; i++ {
+		tr.Step()
+	}
+	best := tr.Population[0].InferredLayer
+	for _, save := range []func(string, Layer) error{SaveToFile, SaveCompressed} {
+		path := filepath.Join(t.TempDir(), "net")
+		if err := save(path, best); err != nil {
+			t.Fatal(err)
+		}
+		l, err := LoadFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, input := range [][]byte{make([]byte, 9), {1, 0, 2, 0, 1, 0, 2, 0, 0}} {
+			tr.Input.Set(input)
+			want := best.GetValues()
+			got, err := Infer(l, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("values %v for input %v, want %v", got, input, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package neural
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// Returned by CheckLengths when the slices have different lengths.
+var ErrLengthMismatch = errors.New("length mismatch")
+
+// The score given to a network whose evaluation failed, so that it sorts last and is replaced.
+const FailedScore = math.MinInt32
+
+// Scores the output of a network for the input it was given. Evaluate is called concurrently from multiple
+// goroutines, so implementations must be safe for concurrent use. Any randomness should come from r, which is owned
+// by the calling goroutine. An error means the network can't be scored for this task (e.g., its output has the wrong
+// size), in which case the trainer gives it FailedScore and moves on.
+type Fitness interface {
+	Evaluate(r *rand.Rand, input StaticLayer, output []byte) (int, error)
+}
+
+// Returns ErrLengthMismatch unless all the slices have the same length. Fitness implementations should use this to
+// validate their arguments so that a misconfigured network fails the same way regardless of the task.
+func CheckLengths(values ...[]byte) error {
+	for _, v := range values[1:] {
+		if len(v) != len(values[0]) {
+			return ErrLengthMismatch
+		}
+	}
+	return nil
+}
+
+// Returns the number of bits that differ between a and b, or ErrLengthMismatch if they have different lengths.
+func HammingDistance(a, b []byte) (int, error) {
+	if err := CheckLengths(a, b); err != nil {
+		return 0, err
+	}
+	var distance int
+	for i := range a {
+		distance += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return distance, nil
+}
@@ -0,0 +1,52 @@
+package neural
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestHammingDistance(t *testing.T) {
+	cases := []struct {
+		name     string
+		a, b     []byte
+		distance int
+		err      error
+	}{
+		{"empty", nil, nil, 0, nil},
+		{"equal", []byte{0x12, 0x34}, []byte{0x12, 0x34}, 0, nil},
+		{"full", []byte{0x00, 0xff}, []byte{0xff, 0x00}, 16, nil},
+		{"partial", []byte{0x0f, 0x01}, []byte{0x00, 0x03}, 5, nil},
+		{"length mismatch", []byte{1, 2}, []byte{1}, 0, ErrLengthMismatch},
+	}
+	for _, c := range cases {
+		d, err := HammingDistance(c.a, c.b)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: got error %v, want %v", c.name, err, c.err)
+		}
+		if d != c.distance {
+			t.Errorf("%s: got distance %d, want %d", c.name, d, c.distance)
+		}
+	}
+}
+
+func TestHammingFitnessInputSize(t *testing.T) {
+	f := &HammingFitness{Data: Dataset{
+		{Input: []byte{1, 2}, Target: []byte{3}},
+		{Input: []byte{4}, Target: []byte{5}},
+	}}
+	r := rand.New(rand.NewSource(1))
+	input := make(StaticLayer, 2)
+	f.Episode(r, input)
+	if !bytes.Equal(input, []byte{1, 2}) {
+		t.Errorf("got input %v, want [1 2]", input)
+	}
+	if score, err := f.Evaluate(r, input, []byte{3}); err != nil || score != 0 {
+		t.Errorf("got score %d and error %v, want 0 and no error", score, err)
+	}
+	f.Episode(r, input)
+	if _, err := f.Evaluate(r, input, []byte{5}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("got error %v for an example of the wrong size, want %v", err, ErrLengthMismatch)
+	}
+}
@@ -0,0 +1,58 @@
+package neural
+
+import (
+	"fmt"
+)
+
+// Returns the And and Xor masks of every edge in the network as a flat slice of bytes. The masks are ordered from the
+// lowest inferred layer to l, then by node and by edge, with each edge's And followed by its Xor. Other properties
+// (topology, operations and biases) are not included, so the genome can only be loaded back into a network with the
+// same topology.
+func Genome(l *InferredLayer) []byte {
+	var g []byte
+	for _, il := range inferredChain(l) {
+		for _, n := range il.Nodes {
+			for _, e := range n.Inputs {
+				g = append(g, e.And, e.Xor)
+			}
+		}
+	}
+	return g
+}
+
+// Writes a genome created with Genome back into the edges of a network. Returns an error unless the genome is exactly
+// two bytes per edge of the network.
+func LoadGenome(l *InferredLayer, g []byte) error {
+	chain := inferredChain(l)
+	var edges int
+	for _, il := range chain {
+		for _, n := range il.Nodes {
+			edges += len(n.Inputs)
+		}
+	}
+	if len(g) != edges*2 {
+		return fmt.Errorf("genome has %d bytes but the network needs %d", len(g), edges*2)
+	}
+	for _, il := range chain {
+		il.Invalidate()
+		for i := range il.Nodes {
+			for j := range il.Nodes[i].Inputs {
+				il.Nodes[i].Inputs[j].And, il.Nodes[i].Inputs[j].Xor = g[0], g[1]
+				g = g[2:]
+			}
+		}
+	}
+	return nil
+}
+
+// Returns l and the inferred layers to the left of it, starting with the lowest one.
+func inferredChain(l *InferredLayer) []*InferredLayer {
+	var chain []*InferredLayer
+	for ok := true; ok; l, ok = l.Left.(*InferredLayer) {
+		chain = append(chain, l)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
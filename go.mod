@@ -0,0 +1,3 @@
+module github.com/blixt/neural
+
+go 1.21
@@ -0,0 +1,88 @@
+package neural
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The version of the binary format written by EncodeLayer and SavePopulation. Version 1 had no header and is still
+// read: fields added since then (e.g. Node.Activation and Edge.Stability) are missing from it and get their zero
+// values, which keep the behavior of networks from before they existed. Versions 1 and 2 encoded the layers themselves,
+// so a layer that was read from more than once was decoded as separate copies. Since version 3 the layers are encoded
+// as the same records as ToJSON writes, which keep such layers shared.
+const FormatVersion = 3
+
+// Returned when decoding data written in a newer version of the format than FormatVersion.
+var ErrFormatVersion = errors.New("unsupported format version")
+
+// Precedes the version number in the header. Its first byte can't start a gob stream, which is how headerless version
+// 1 data is recognized.
+var formatMagic = []byte{0x89, 'N', 'R', 'L'}
+
+func init() {
+	gob.Register(&InferredLayer{})
+	gob.Register(&ScoredLayer{})
+	gob.Register(&RecurrentLayer{})
+	gob.Register(&MaxPoolLayer{})
+	gob.Register(&NotLayer{})
+	gob.Register(&ShiftLayer{})
+	gob.Register(&LUTLayer{})
+	gob.Register(&ConcatLayer{})
+	gob.Register(&DAGLayer{})
+	gob.Register(StaticLayer{})
+}
+
+// Writes a layer and everything to the left of it to w in gob format, preceded by a header with the FormatVersion.
+func EncodeLayer(w io.Writer, l Layer) error {
+	rec, err := newLayerEncoder(l).encode(l)
+	if err != nil {
+		return err
+	}
+	return encodeVersioned(w, rec)
+}
+
+// Reads a layer previously written with EncodeLayer by this or an earlier version of the package. The result shares no
+// memory with the encoded layer.
+func DecodeLayer(r io.Reader) (Layer, error) {
+	var l Layer
+	var rec layerRecord
+	version, err := decodeVersioned(r, &l, &rec)
+	if err != nil {
+		return nil, err
+	}
+	if version < 3 {
+		return l, nil
+	}
+	return newLayerDecoder().decode(&rec)
+}
+
+// Writes the header followed by the gob encoding of v.
+func encodeVersioned(w io.Writer, v any) error {
+	if _, err := w.Write(append(formatMagic[:len(formatMagic):len(formatMagic)], FormatVersion)); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(v)
+}
+
+// Reads what encodeVersioned wrote into v, or what versions before 3 wrote into old, and returns the version of the
+// data. Data without a header is version 1.
+func decodeVersioned(r io.Reader, old, v any) (int, error) {
+	br := bufio.NewReader(r)
+	version := 1
+	header, err := br.Peek(len(formatMagic) + 1)
+	if err == nil && bytes.Equal(header[:len(formatMagic)], formatMagic) {
+		if version = int(header[len(formatMagic)]); version > FormatVersion {
+			return 0, fmt.Errorf("%w %d, only versions up to %d are supported", ErrFormatVersion, version, FormatVersion)
+		}
+		br.Discard(len(header))
+	}
+	if version < 3 {
+		v = old
+	}
+	// Versions 1 and 2 only differ in the header since gob leaves fields that are missing from the data unchanged.
+	return version, gob.NewDecoder(br).Decode(v)
+}
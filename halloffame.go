@@ -0,0 +1,62 @@
+package neural
+
+import (
+	"sort"
+)
+
+// Keeps the highest scoring networks ever seen, separately from the population so that they survive even if a good
+// network later scores badly and dies out. Networks are copied when they enter, so later mutations don't affect them.
+type HallOfFame struct {
+	// The maximum number of networks to keep.
+	Size int
+	// The networks, sorted by descending score. Each keeps the best score it was observed with.
+	Networks []ScoredLayer
+
+	// The network each entry was copied from, so that a network that is scored again every generation (e.g. an elite)
+	// is only kept once.
+	sources []*InferredLayer
+}
+
+// Adds a copy of the network if it scores high enough, and returns whether it was added. A network that is already in
+// the hall only has its score updated, and only if the new score is higher.
+func (h *HallOfFame) Add(l ScoredLayer) bool {
+	if l.Score == FailedScore {
+		return false
+	}
+	for i, s := range h.sources {
+		if s != l.InferredLayer {
+			continue
+		}
+		if l.Score <= h.Networks[i].Score {
+			return false
+		}
+		h.Networks = append(h.Networks[:i], h.Networks[i+1:]...)
+		h.sources = append(h.sources[:i], h.sources[i+1:]...)
+		break
+	}
+	if len(h.Networks) >= h.Size && (h.Size <= 0 || l.Score <= h.Networks[len(h.Networks)-1].Score) {
+		return false
+	}
+	i := sort.Search(len(h.Networks), func(i int) bool {
+		return h.Networks[i].Score < l.Score
+	})
+	h.Networks = append(h.Networks, ScoredLayer{})
+	copy(h.Networks[i+1:], h.Networks[i:])
+	h.Networks[i] = ScoredLayer{l.CopyInferred(), l.Score}
+	h.sources = append(h.sources, nil)
+	copy(h.sources[i+1:], h.sources[i:])
+	h.sources[i] = l.InferredLayer
+	if len(h.Networks) > h.Size {
+		h.Networks = h.Networks[:h.Size]
+		h.sources = h.sources[:h.Size]
+	}
+	return true
+}
+
+// Returns the highest scoring network in the hall, or nil if it's empty.
+func (h *HallOfFame) Best() *ScoredLayer {
+	if len(h.Networks) == 0 {
+		return nil
+	}
+	return &h.Networks[0]
+}
@@ -0,0 +1,206 @@
+package neural
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"slices"
+	"sort"
+)
+
+// Returns a hash of the structure of a network: its layer types and sizes, and the edges and biases of its nodes.
+// Structurally identical networks have the same hash even if their edges are stored in a different order, since the
+// order doesn't affect the values. Input values, recurrent state and scores aren't included. A layer that is read from
+// more than once is hashed in full the first time it's reached and as a reference to that afterwards, so which layers
+// are shared is part of the structure, and every layer is hashed once however often it's read from.
+func Hash(l Layer) uint64 {
+	h := fnv.New64a()
+	hashLayer(h, l, make(map[any]int))
+	return h.Sum64()
+}
+
+// Hashes l and the layers it reads from, numbering the layers in the order they're first reached in seen.
+func hashLayer(h hash.Hash64, l Layer, seen map[any]int) {
+	l = unwrap(l)
+	key := layerKey(l)
+	if n, ok := seen[key]; ok {
+		hashInts(h, 10, n)
+		return
+	}
+	seen[key] = len(seen)
+	switch l := l.(type) {
+	case *InferredLayer:
+		hashInts(h, 1)
+		hashNodes(h, l.Nodes)
+		hashLayer(h, l.Left, seen)
+	case *RecurrentLayer:
+		hashInts(h, 2)
+		hashNodes(h, l.Nodes)
+		hashLayer(h, l.Left, seen)
+	case *MaxPoolLayer:
+		hashInts(h, 3, l.Window)
+		hashLayer(h, l.Left, seen)
+	case *NotLayer:
+		hashInts(h, 7)
+		hashLayer(h, l.Left, seen)
+	case *ShiftLayer:
+		hashInts(h, 8, l.Shift)
+		hashLayer(h, l.Left, seen)
+	case *LUTLayer:
+		hashInts(h, 9, len(l.Nodes))
+		for _, n := range l.Nodes {
+			hashInts(h, len(n.Inputs))
+			for _, in := range n.Inputs {
+				hashInts(h, in.Index, int(in.Bit))
+			}
+			h.Write(n.Table[:])
+		}
+		hashLayer(h, l.Left, seen)
+	case *ConcatLayer:
+		hashInts(h, 4, len(l.Layers))
+		for _, c := range l.Layers {
+			hashLayer(h, c, seen)
+		}
+	case *DAGLayer:
+		hashInts(h, 6, len(l.Lefts))
+		hashNodes(h, l.Nodes)
+		for _, left := range l.Lefts {
+			hashLayer(h, left, seen)
+		}
+	case StaticLayer:
+		hashInts(h, 5, len(l))
+	default:
+		hashInts(h, 0, l.Size())
+	}
+}
+
+func hashNodes(h hash.Hash64, nodes []Node) {
+	hashInts(h, len(nodes))
+	var edges []Edge
+	for _, n := range nodes {
+		edges = append(edges[:0], n.Inputs...)
+		sort.Slice(edges, func(i, j int) bool {
+			a, b := edges[i], edges[j]
+			if a.Index != b.Index {
+				return a.Index < b.Index
+			}
+			if a.Op != b.Op {
+				return a.Op < b.Op
+			}
+			if a.And != b.And {
+				return a.And < b.And
+			}
+			if a.Xor != b.Xor {
+				return a.Xor < b.Xor
+			}
+			return a.Stability < b.Stability
+		})
+		hashInts(h, len(edges))
+		h.Write([]byte{n.Bias, byte(n.Activation), n.Threshold, n.Max})
+		for _, e := range edges {
+			hashInts(h, e.Index)
+			h.Write([]byte{e.And, e.Xor, byte(e.Op), e.Stability})
+		}
+	}
+}
+
+func hashInts(h hash.Hash64, values ...int) {
+	var b [binary.MaxVarintLen64]byte
+	for _, v := range values {
+		h.Write(b[:binary.PutVarint(b[:], int64(v))])
+	}
+}
+
+// Reports whether two networks have the same structure: the same layer types and sizes, the same edges (in the same
+// order) and biases in every node, and the same layers read from more than once. Like Hash, input values, recurrent
+// state and scores aren't compared, so networks that are Equal have the same Hash.
+func Equal(a, b Layer) bool {
+	return equalLayers(a, b, make(map[any]any), make(map[any]any))
+}
+
+// Compares a and b and the layers they read from, recording which layer of b each layer of a corresponds to in ab and
+// the reverse in ba, so that shared layers are compared once and must be shared the same way in both networks.
+func equalLayers(a, b Layer, ab, ba map[any]any) bool {
+	a, b = unwrap(a), unwrap(b)
+	ka, kb := layerKey(a), layerKey(b)
+	if k, ok := ab[ka]; ok {
+		return k == kb
+	}
+	if _, ok := ba[kb]; ok {
+		return false
+	}
+	ab[ka], ba[kb] = kb, ka
+	switch a := a.(type) {
+	case *InferredLayer:
+		b, ok := b.(*InferredLayer)
+		return ok && equalNodes(a.Nodes, b.Nodes) && equalLayers(a.Left, b.Left, ab, ba)
+	case *RecurrentLayer:
+		b, ok := b.(*RecurrentLayer)
+		return ok && equalNodes(a.Nodes, b.Nodes) && equalLayers(a.Left, b.Left, ab, ba)
+	case *MaxPoolLayer:
+		b, ok := b.(*MaxPoolLayer)
+		return ok && a.Window == b.Window && equalLayers(a.Left, b.Left, ab, ba)
+	case *NotLayer:
+		b, ok := b.(*NotLayer)
+		return ok && equalLayers(a.Left, b.Left, ab, ba)
+	case *ShiftLayer:
+		b, ok := b.(*ShiftLayer)
+		return ok && a.Shift == b.Shift && equalLayers(a.Left, b.Left, ab, ba)
+	case *LUTLayer:
+		b, ok := b.(*LUTLayer)
+		if !ok || len(a.Nodes) != len(b.Nodes) {
+			return false
+		}
+		for i, n := range a.Nodes {
+			if n.Table != b.Nodes[i].Table || !slices.Equal(n.Inputs, b.Nodes[i].Inputs) {
+				return false
+			}
+		}
+		return equalLayers(a.Left, b.Left, ab, ba)
+	case *ConcatLayer:
+		b, ok := b.(*ConcatLayer)
+		if !ok || len(a.Layers) != len(b.Layers) {
+			return false
+		}
+		for i := range a.Layers {
+			if !equalLayers(a.Layers[i], b.Layers[i], ab, ba) {
+				return false
+			}
+		}
+		return true
+	case *DAGLayer:
+		b, ok := b.(*DAGLayer)
+		if !ok || len(a.Lefts) != len(b.Lefts) || !equalNodes(a.Nodes, b.Nodes) {
+			return false
+		}
+		for i := range a.Lefts {
+			if !equalLayers(a.Lefts[i], b.Lefts[i], ab, ba) {
+				return false
+			}
+		}
+		return true
+	case StaticLayer:
+		b, ok := b.(StaticLayer)
+		return ok && len(a) == len(b)
+	default:
+		return a == nil && b == nil
+	}
+}
+
+func equalNodes(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Bias != b[i].Bias || a[i].Activation != b[i].Activation || a[i].Threshold != b[i].Threshold ||
+			a[i].Max != b[i].Max || len(a[i].Inputs) != len(b[i].Inputs) {
+			return false
+		}
+		for j := range a[i].Inputs {
+			if a[i].Inputs[j] != b[i].Inputs[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
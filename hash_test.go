@@ -0,0 +1,38 @@
+package neural
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	newNetwork := func(depth, width int) *InferredLayer {
+		r := rand.New(rand.NewSource(1))
+		var l Layer = make(StaticLayer, 3)
+		for i := 0; i < depth; i++ {
+			l = NewFullyConnectedLayerWithRand(r, l, width)
+		}
+		return l.(*InferredLayer)
+	}
+	l := newNetwork(2, 4)
+	flipped := l.CopyInferred()
+	flipped.Left.(*InferredLayer).Nodes[1].Inputs[2].Xor ^= 0x10
+	cases := []struct {
+		name  string
+		other Layer
+		equal bool
+	}{
+		{"copy", l.CopyInferred(), true},
+		{"different depth", newNetwork(3, 4), false},
+		{"different width", newNetwork(2, 5), false},
+		{"single edge bit", flipped, false},
+	}
+	for _, c := range cases {
+		if got := Equal(l, c.other); got != c.equal {
+			t.Errorf("%s: Equal is %v, want %v", c.name, got, c.equal)
+		}
+		if got := Hash(l) == Hash(c.other); got != c.equal {
+			t.Errorf("%s: equal hashes is %v, want %v", c.name, got, c.equal)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package neural
+
+// Evaluates a network, which must read from a single input layer, for the given input and returns its output. This is
+// all that's needed to use an evolved network, e.g. one loaded with FromJSON in a WebAssembly build, and only the
+// result is allocated for networks whose layers cache their values. The input layer is overwritten with input, so
+// Infer must not be called concurrently for networks that share it.
+func Infer(net Layer, input []byte) ([]byte, error) {
+	in, err := inputOf(net)
+	if err != nil {
+		return nil, err
+	}
+	if err := in.Set(input); err != nil {
+		return nil, err
+	}
+	out := make([]byte, net.Size())
+	copy(out, valuesOf(net))
+	return out, nil
+}
@@ -0,0 +1,278 @@
+package neural
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The serialized form of a layer, used by ToJSON and by the binary format since version 3. The type field selects
+// which of the other fields are used. A layer that is read from more than once (e.g. an input shared by both sides of a
+// ConcatLayer) is written in full the first time it's reached, with an ID, and as a record with only Ref set to that ID
+// every time after that, so that it's still shared when it's read back.
+type layerRecord struct {
+	Type   string         `json:"type,omitempty"`
+	ID     int            `json:"id,omitempty"`
+	Ref    int            `json:"ref,omitempty"`
+	Nodes  []Node         `json:"nodes,omitempty"`
+	LUT    []LUTNode      `json:"lut,omitempty"`
+	Values []byte         `json:"values,omitempty"`
+	Score  int            `json:"score,omitempty"`
+	Window int            `json:"window,omitempty"`
+	Shift  int            `json:"shift,omitempty"`
+	Frozen bool           `json:"frozen,omitempty"`
+	Left   *layerRecord   `json:"left,omitempty"`
+	Layers []*layerRecord `json:"layers,omitempty"`
+}
+
+// Serializes a layer and everything to the left of it as JSON.
+func ToJSON(l Layer) ([]byte, error) {
+	rec, err := newLayerEncoder(l).encode(l)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rec)
+}
+
+// Deserializes a layer previously serialized with ToJSON.
+func FromJSON(data []byte) (Layer, error) {
+	var rec layerRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return newLayerDecoder().decode(&rec)
+}
+
+// Turns layers into records, keeping track of the layers that are reached more than once.
+type layerEncoder struct {
+	// The number of times each layer is reached, by layerKey, and the IDs of the shared layers written so far.
+	refs   map[any]int
+	ids    map[any]int
+	lastID int
+}
+
+// Returns an encoder for the layers of roots. Layers shared between the roots are written once, like layers shared
+// within a network.
+func newLayerEncoder(roots ...Layer) *layerEncoder {
+	e := &layerEncoder{refs: make(map[any]int), ids: make(map[any]int)}
+	var count func(l Layer)
+	count = func(l Layer) {
+		key := layerKey(l)
+		if e.refs[key]++; e.refs[key] == 1 {
+			for _, left := range lefts(l) {
+				count(left)
+			}
+		}
+	}
+	for _, l := range roots {
+		count(l)
+	}
+	return e
+}
+
+// Returns the record of l, or a reference to it if it has been encoded before.
+func (e *layerEncoder) encode(l Layer) (*layerRecord, error) {
+	key := layerKey(l)
+	if id, ok := e.ids[key]; ok {
+		return &layerRecord{Ref: id}, nil
+	}
+	rec, err := e.record(l)
+	if err != nil {
+		return nil, err
+	}
+	if e.refs[key] > 1 {
+		e.lastID++
+		e.ids[key], rec.ID = e.lastID, e.lastID
+	}
+	return rec, nil
+}
+
+func (e *layerEncoder) record(l Layer) (*layerRecord, error) {
+	switch l := l.(type) {
+	case *InferredLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "inferred", Nodes: l.Nodes, Frozen: l.Frozen, Left: left}, nil
+	case *ScoredLayer:
+		return e.record(*l)
+	case ScoredLayer:
+		jl, err := e.encode(l.InferredLayer)
+		if err != nil {
+			return nil, err
+		}
+		jl.Type = "scored"
+		jl.Score = l.Score
+		return jl, nil
+	case *RecurrentLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "recurrent", Nodes: l.Nodes, Values: l.State, Left: left}, nil
+	case *MaxPoolLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "maxpool", Window: l.Window, Left: left}, nil
+	case *NotLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "not", Left: left}, nil
+	case *ShiftLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "shift", Shift: l.Shift, Left: left}, nil
+	case *LUTLayer:
+		left, err := e.encode(l.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &layerRecord{Type: "lut", LUT: l.Nodes, Left: left}, nil
+	case *ConcatLayer:
+		jl := &layerRecord{Type: "concat", Layers: make([]*layerRecord, len(l.Layers))}
+		for i, c := range l.Layers {
+			var err error
+			if jl.Layers[i], err = e.encode(c); err != nil {
+				return nil, err
+			}
+		}
+		return jl, nil
+	case *DAGLayer:
+		jl := &layerRecord{Type: "dag", Nodes: l.Nodes, Layers: make([]*layerRecord, len(l.Lefts))}
+		for i, left := range l.Lefts {
+			var err error
+			if jl.Layers[i], err = e.encode(left); err != nil {
+				return nil, err
+			}
+		}
+		return jl, nil
+	case StaticLayer:
+		return &layerRecord{Type: "static", Values: l}, nil
+	default:
+		return nil, fmt.Errorf("cannot serialize layer of type %T", l)
+	}
+}
+
+// Turns records back into layers, resolving references to shared layers.
+type layerDecoder struct {
+	layers map[int]Layer
+}
+
+func newLayerDecoder() *layerDecoder {
+	return &layerDecoder{layers: make(map[int]Layer)}
+}
+
+// Returns the layer of a record, which is the same layer every time for references to the same ID.
+func (d *layerDecoder) decode(rec *layerRecord) (Layer, error) {
+	if rec == nil {
+		return nil, errors.New("missing layer")
+	}
+	if rec.Ref != 0 {
+		l, ok := d.layers[rec.Ref]
+		if !ok {
+			return nil, fmt.Errorf("reference to unknown layer %d", rec.Ref)
+		}
+		return l, nil
+	}
+	l, err := d.layer(rec)
+	if err != nil {
+		return nil, err
+	}
+	if rec.ID != 0 {
+		if _, ok := d.layers[rec.ID]; ok {
+			return nil, fmt.Errorf("duplicate layer id %d", rec.ID)
+		}
+		d.layers[rec.ID] = l
+	}
+	return l, nil
+}
+
+func (d *layerDecoder) layer(jl *layerRecord) (Layer, error) {
+	switch jl.Type {
+	case "inferred", "scored":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		l := &InferredLayer{Nodes: jl.Nodes, Left: left, Frozen: jl.Frozen}
+		if jl.Type == "scored" {
+			return &ScoredLayer{l, jl.Score}, nil
+		}
+		return l, nil
+	case "recurrent":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		state := make([]byte, len(jl.Nodes))
+		copy(state, jl.Values)
+		return &RecurrentLayer{Nodes: jl.Nodes, Left: left, State: state}, nil
+	case "maxpool":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		l, err := NewMaxPoolLayer(left, jl.Window)
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	case "not":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		return &NotLayer{Left: left}, nil
+	case "shift":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		return &ShiftLayer{Left: left, Shift: jl.Shift}, nil
+	case "lut":
+		left, err := d.left(jl)
+		if err != nil {
+			return nil, err
+		}
+		return &LUTLayer{Nodes: jl.LUT, Left: left}, nil
+	case "concat":
+		l := &ConcatLayer{Layers: make([]Layer, len(jl.Layers))}
+		for i, c := range jl.Layers {
+			var err error
+			if l.Layers[i], err = d.decode(c); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case "dag":
+		l := &DAGLayer{Nodes: jl.Nodes, Lefts: make([]Layer, len(jl.Layers))}
+		for i, left := range jl.Layers {
+			var err error
+			if l.Lefts[i], err = d.decode(left); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case "static":
+		if jl.Values == nil {
+			return StaticLayer{}, nil
+		}
+		return StaticLayer(jl.Values), nil
+	default:
+		return nil, fmt.Errorf("unknown layer type %q", jl.Type)
+	}
+}
+
+func (d *layerDecoder) left(jl *layerRecord) (Layer, error) {
+	if jl.Left == nil {
+		return nil, fmt.Errorf("%s layer is missing its left layer", jl.Type)
+	}
+	return d.decode(jl.Left)
+}
@@ -0,0 +1,124 @@
+package neural
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONScoredLayer(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := StaticLayer{1, 2, 3}
+	s := ScoredLayer{InferredLayer: NewFullyConnectedLayerWithRand(r, in, 4), Score: 7}
+	for _, l := range []Layer{s, &s} {
+		data, err := ToJSON(l)
+		if err != nil {
+			t.Fatalf("%T: %v", l, err)
+		}
+		if strings.Contains(string(data), `"Inputs"`) || !strings.Contains(string(data), `"inputs"`) {
+			t.Errorf("%T: node keys aren't lowercase: %s", l, data)
+		}
+		got, err := FromJSON(data)
+		if err != nil {
+			t.Fatalf("%T: %v", l, err)
+		}
+		g, ok := got.(*ScoredLayer)
+		if !ok || g.Score != s.Score || !Equal(g, s) {
+			t.Errorf("%T: round trip gave %v", l, got)
+		}
+		if !bytes.Equal(g.GetValues(), s.GetValues()) {
+			t.Errorf("%T: values %v, want %v", l, g.GetValues(), s.GetValues())
+		}
+	}
+}
+
+// Round-trips l with ToJSON and FromJSON, EncodeLayer and DecodeLayer, and SavePopulation and LoadPopulation.
+func roundTrips(t *testing.T, l *InferredLayer) map[string]Layer {
+	t.Helper()
+	results := make(map[string]Layer)
+	data, err := ToJSON(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["json"], err = FromJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := EncodeLayer(&buf, l); err != nil {
+		t.Fatal(err)
+	}
+	if results["gob"], err = DecodeLayer(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pop")
+	if err := SavePopulation(path, []ScoredLayer{{InferredLayer: l}, {InferredLayer: l.CopyInferred()}}); err != nil {
+		t.Fatal(err)
+	}
+	pop, err := LoadPopulation(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results["population"] = pop[0].InferredLayer
+	return results
+}
+
+func TestRoundTripSharedInput(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := StaticLayer{1, 2, 3, 4}
+	l := NewFullyConnectedLayerWithRand(r, NewConcatLayer(in, &ShiftLayer{Left: in, Shift: 1}), 5)
+	for name, got := range roundTrips(t, l) {
+		if !Equal(got, l) {
+			t.Errorf("%s: round trip changed the network", name)
+		}
+		for _, input := range [][]byte{{1, 2, 3, 4}, {9, 8, 7, 6}} {
+			in.Set(input)
+			out, err := Infer(got, input)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if want := l.GetValues(); !bytes.Equal(out, want) {
+				t.Errorf("%s: values %v for input %v, want %v", name, out, input, want)
+			}
+		}
+	}
+}
+
+func TestRoundTripDAGSkipConnection(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	in := StaticLayer{1, 2, 3}
+	hidden := NewFullyConnectedLayerWithRand(r, in, 4)
+	dag := NewDAGLayerWithRand(r, 5, hidden, in)
+	l := NewFullyConnectedLayerWithRand(r, dag, 2)
+	for name, got := range roundTrips(t, l) {
+		d := got.(*InferredLayer).Left.(*DAGLayer)
+		if d.Lefts[0].(*InferredLayer).Left.(StaticLayer)[0] = 42; d.Lefts[1].(StaticLayer)[0] != 42 {
+			t.Errorf("%s: the DAG and its left layer read from different inputs", name)
+		}
+		for _, input := range [][]byte{{1, 2, 3}, {9, 8, 7}} {
+			in.Set(input)
+			out, err := Infer(got, input)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if want := l.GetValues(); !bytes.Equal(out, want) {
+				t.Errorf("%s: values %v for input %v, want %v", name, out, input, want)
+			}
+		}
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"concat","layers":[null]}`,
+		`{"type":"dag","nodes":[],"layers":[{"type":"static","values":"AQI="},null]}`,
+		`{"type":"inferred","nodes":[]}`,
+		`{"type":"concat","layers":[{"ref":1}]}`,
+		`{"type":"bogus"}`,
+	} {
+		if l, err := FromJSON([]byte(data)); err == nil {
+			t.Errorf("%s: decoded as %v without an error", data, l)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Package neural evolves networks of bitwise nodes with a genetic algorithm.
+package neural
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// A layer of values. A layer can be empty, e.g. an empty StaticLayer or a layer created with a size of 0, in which case
+// GetValues returns an empty slice and the nodes of layers reading from it have no edges to it, so they output their
+// biases.
+//
+// Evaluating a layer updates caches, scratch buffers and recurrent state in it and in the layers below it, so a network
+// must not be evaluated from more than one goroutine at a time. Copies made with Copy only share their input layers,
+// which are read but never written during evaluation, so separate copies can be evaluated concurrently.
+type Layer interface {
+	Copy() Layer
+	GetValues() []byte
+	Size() int
+}
+
+// A node that accumulates a value from one or more connections to the previous layer.
+type Node struct {
+	// The constructors and mutations keep the edges sorted by Index, with edges to the same index in the order they were
+	// added, so that networks with the same edges also list them in the same order. Apart from Hash, which doesn't
+	// depend on the order, nodes and edges are always processed in slice order.
+	Inputs []Edge `json:"inputs"`
+	// A constant that is XORed into the accumulated value.
+	Bias byte `json:"bias,omitempty"`
+	// What the node outputs for the accumulated value, which by default is the value itself.
+	Activation Activation `json:"activation,omitempty"`
+	// For ActivationThreshold, the number of bits in the accumulated value that must be exceeded for the node to output
+	// 1.
+	Threshold byte `json:"threshold,omitempty"`
+	// If non-zero, the accumulated value saturates at Max: larger values are clamped to it before the activation is
+	// applied. With ActivationThreshold this also limits how many bits can be set.
+	Max byte `json:"max,omitempty"`
+}
+
+// A function that a node applies to its accumulated value.
+type Activation byte
+
+const (
+	// The accumulated value is output as is.
+	ActivationNone Activation = iota
+	// The node outputs 1 if more than Threshold bits of the accumulated value are set, and 0 otherwise, like a
+	// perceptron.
+	ActivationThreshold
+
+	numActivations
+)
+
+// Returns the output of the node for its accumulated value.
+func (n Node) activate(v byte) byte {
+	if n.Max != 0 {
+		v = min(v, n.Max)
+	}
+	if n.Activation == ActivationThreshold {
+		if bits.OnesCount8(v) > int(n.Threshold) {
+			return 1
+		}
+		return 0
+	}
+	return v
+}
+
+// A single connection between two nodes in two adjacent layers.
+type Edge struct {
+	Index int  `json:"index"`
+	And   byte `json:"and"`
+	Xor   byte `json:"xor"`
+	// The operation combining the input value with And. Xor is always applied afterwards.
+	Op Op `json:"op,omitempty"`
+	// Makes Mutate change the edge less often: it's mutated with a probability of 1/(rarity*(Stability+1)) instead of
+	// 1/rarity. Mutations occasionally move it up or down by one, and crossover and copies keep it along with the rest of
+	// the edge, so it evolves with the network.
+	Stability byte `json:"stability,omitempty"`
+}
+
+// An operation that an edge applies to its input value.
+type Op byte
+
+const (
+	// value & And ^ Xor
+	OpAnd Op = iota
+	// (value | And) ^ Xor
+	OpOr
+	// ^(value & And) ^ Xor
+	OpNand
+
+	numOps
+)
+
+// Returns the value that the edge contributes to its node for the given input value.
+func (e Edge) apply(v byte) byte {
+	switch e.Op {
+	case OpOr:
+		return (v | e.And) ^ e.Xor
+	case OpNand:
+		return ^(v & e.And) ^ e.Xor
+	default:
+		return v&e.And ^ e.Xor
+	}
+}
+
+// A layer that is inferred from the previous layer ("left"). It caches the values of its last evaluation, so like any
+// layer it must not be evaluated from more than one goroutine at a time (see Layer).
+type InferredLayer struct {
+	Nodes []Node
+	Left  Layer
+	// If set, mutations leave the nodes and edges of this layer unchanged, while still mutating the layers to the left
+	// of it unless they're frozen too.
+	Frozen bool
+
+	// The values from the last evaluation, and the left values they were computed from. The cache is only used while
+	// cached is set and the left values haven't changed.
+	cache, cacheLeft []byte
+	cached           bool
+}
+
+func (l InferredLayer) Copy() Layer {
+	return l.CopyInferred()
+}
+
+// Like Copy, but returns the concrete type so that callers don't need a type assertion. On a ScoredLayer this copies
+// just the network, without its score. Layers that are read from more than once stay shared in the copy.
+func (l InferredLayer) CopyInferred() *InferredLayer {
+	return &InferredLayer{
+		Nodes:  copyNodes(l.Nodes),
+		Left:   copyShared(l.Left, make(map[any]Layer)),
+		Frozen: l.Frozen,
+	}
+}
+
+// Returns a copy of the nodes that doesn't share any edges with them.
+func copyNodes(nodes []Node) []Node {
+	c := make([]Node, len(nodes))
+	for i, n := range nodes {
+		c[i] = n
+		c[i].Inputs = make([]Edge, len(n.Inputs))
+		copy(c[i].Inputs, n.Inputs)
+	}
+	return c
+}
+
+// Returns a copy of the values, computing them only if the left values have changed since the last evaluation. Since
+// this updates the cache, it must not be called concurrently with another evaluation of the network.
+func (l *InferredLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	copy(v, l.values())
+	return v
+}
+
+// Like GetValues, but writes the values into dst instead of allocating a new slice. Panics unless dst is exactly
+// Size() bytes long.
+func (l *InferredLayer) GetValuesInto(dst []byte) {
+	v := l.values()
+	if err := CheckLengths(dst, v); err != nil {
+		panic(err)
+	}
+	copy(dst, v)
+}
+
+// Discards the cached values of this layer. Changes made by Mutate and changes to the left layer's values are
+// detected automatically, so this is only needed after modifying Nodes directly.
+func (l *InferredLayer) Invalidate() {
+	l.cached = false
+}
+
+// Like GetValues, but returns the cached values instead of a copy. The result must not be modified.
+//
+// The left values are fetched once per call and without copying, and if they haven't changed since the last call the
+// nodes aren't computed again. The layers below are still visited to fetch them, though, so a layer that is read from
+// along several paths (e.g. through both sides of a ConcatLayer) is visited once per path. A DAGLayer evaluates the
+// layers below it once each instead.
+func (l *InferredLayer) values() []byte {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	if l.cached && bytes.Equal(lv, l.cacheLeft) {
+		return l.cache
+	}
+	// Reuse the cache buffer since nothing outside the layer can hold a reference to it.
+	v := resize(l.cache, len(l.Nodes))
+	computeNodes(l.Nodes, lv, v)
+	l.cache = v
+	l.cacheLeft = append(l.cacheLeft[:0], lv...)
+	l.cached = true
+	return v
+}
+
+// Writes the value of each node, computed from the values in, into dst.
+func computeNodes(nodes []Node, in, dst []byte) {
+	for i, node := range nodes {
+		dst[i] = node.Bias
+		for _, input := range node.Inputs {
+			dst[i] ^= input.apply(in[input.Index])
+		}
+		dst[i] = node.activate(dst[i])
+	}
+}
+
+// Randomly flips bits in the edges of this layer and all inferred layers to the left of it. Each edge is mutated with
+// a probability of 1/rarity, so a higher rarity means fewer mutations. Frozen layers are skipped.
+func (l *InferredLayer) Mutate(r *rand.Rand, rarity int) {
+	if !l.Frozen {
+		l.Invalidate()
+		mutateWeights(r, l.Nodes, rarity)
+	}
+	mutateLeft(r, l.Left, rarity)
+}
+
+// Mutates each bias of the nodes with a probability of 1/rarity, and each edge with a probability of 1/rarity scaled
+// down by its Stability. One in eight edge mutations also picks a new operation, one in eight moves the edge's Stability
+// up or down by one, and a bias mutation flips one of its bits. Thresholds of nodes with ActivationThreshold, and the Max of saturating nodes, are moved up or down by one with
+// the same probability.
+func mutateWeights(r *rand.Rand, nodes []Node, rarity int) {
+	for i := range nodes {
+		if r.Intn(rarity) == 0 {
+			nodes[i].Bias ^= 1 << uint(r.Intn(8))
+		}
+		if nodes[i].Activation == ActivationThreshold && r.Intn(rarity) == 0 {
+			if t := nodes[i].Threshold; t == 0 || t < 7 && r.Intn(2) == 0 {
+				nodes[i].Threshold++
+			} else {
+				nodes[i].Threshold--
+			}
+		}
+		if nodes[i].Max != 0 && r.Intn(rarity) == 0 {
+			if m := nodes[i].Max; m == 1 || m < 255 && r.Intn(2) == 0 {
+				nodes[i].Max++
+			} else {
+				nodes[i].Max--
+			}
+		}
+		for j := range nodes[i].Inputs {
+			if r.Intn(rarity*(int(nodes[i].Inputs[j].Stability)+1)) != 0 {
+				continue
+			}
+			if r.Intn(8) == 0 {
+				nodes[i].Inputs[j].Op = Op(r.Intn(int(numOps)))
+			}
+			if r.Intn(8) == 0 {
+				if s := nodes[i].Inputs[j].Stability; s == 0 || s < 255 && r.Intn(2) == 0 {
+					nodes[i].Inputs[j].Stability++
+				} else {
+					nodes[i].Inputs[j].Stability--
+				}
+			}
+			var v uint64
+			v = r.Uint64()
+			nodes[i].Inputs[j].And |= byte((v >> 56) & (v >> 48) & (v >> 40) & (v >> 32) & (v >> 24) & (v >> 16) & (v >> 8) & v)
+			v = r.Uint64()
+			nodes[i].Inputs[j].And &= byte((v >> 56) | (v >> 48) | (v >> 40) | (v >> 32) | (v >> 24) | (v >> 16) | (v >> 8) | v)
+			v = r.Uint64()
+			nodes[i].Inputs[j].Xor |= byte((v >> 56) & (v >> 48) & (v >> 40) & (v >> 32) & (v >> 24) & (v >> 16) & (v >> 8) & v)
+			v = r.Uint64()
+			nodes[i].Inputs[j].Xor &= byte((v >> 56) | (v >> 48) | (v >> 40) | (v >> 32) | (v >> 24) | (v >> 16) | (v >> 8) | v)
+		}
+	}
+}
+
+// Mutates the left layer of a layer if it's trainable.
+func mutateLeft(r *rand.Rand, left Layer, rarity int) {
+	if m, ok := left.(interface{ Mutate(*rand.Rand, int) }); ok {
+		m.Mutate(r, rarity)
+	}
+}
+
+func (l InferredLayer) Size() int {
+	return len(l.Nodes)
+}
+
+// Utility layer for keeping score.
+type ScoredLayer struct {
+	*InferredLayer
+	Score int
+}
+
+func (l ScoredLayer) Copy() Layer {
+	return &ScoredLayer{l.CopyInferred(), 0}
+}
+
+// Non-trainable layer (i.e., input).
+type StaticLayer []byte
+
+// Returns the layer itself, so that every copy of a network keeps reading from the same input. Use Clone or DeepCopy
+// to get an independent input.
+func (l StaticLayer) Copy() Layer {
+	return l
+}
+
+// Copies values into the layer, which is how new input is given to the networks reading from it. The values are
+// copied so the caller can reuse the slice. Layers reading from the input notice the change on their next evaluation.
+func (l StaticLayer) Set(values []byte) error {
+	if err := CheckLengths(l, values); err != nil {
+		return err
+	}
+	copy(l, values)
+	return nil
+}
+
+// Returns a copy of the layer that doesn't share memory with it.
+func (l StaticLayer) Clone() StaticLayer {
+	return append(StaticLayer{}, l...)
+}
+
+func (l StaticLayer) GetValues() []byte {
+	return l
+}
+
+func (l StaticLayer) Size() int {
+	return len(l)
+}
+
+func (l StaticLayer) values() []byte {
+	return l
+}
+
+// Like Copy, but also clones the input layers of the network so the copy is fully independent. An input that is read
+// by several layers is cloned once, so the copy shares it the same way.
+func DeepCopy(l Layer) Layer {
+	c := l.Copy()
+	if s, ok := c.(StaticLayer); ok {
+		return s.Clone()
+	}
+	clones := make(map[any]StaticLayer)
+	mapInputs(c, func(s StaticLayer) StaticLayer {
+		key := layerKey(s)
+		if _, ok := clones[key]; !ok {
+			clones[key] = s.Clone()
+		}
+		return clones[key]
+	})
+	return c
+}
+
+// Returns a value that identifies a layer and can be used as a map key.
+func layerKey(l Layer) any {
+	if s, ok := l.(StaticLayer); ok {
+		// Slices can't be map keys, but their first element identifies them.
+		if len(s) == 0 {
+			return (*byte)(nil)
+		}
+		return &s[0]
+	}
+	return l
+}
+
+// Returns the values of a layer without copying them if the layer supports it. The result must not be modified.
+func valuesOf(l Layer) []byte {
+	if v, ok := l.(interface{ values() []byte }); ok {
+		return v.values()
+	}
+	return l.GetValues()
+}
+
+// Returns b with length n, reallocating only if its capacity is insufficient.
+func resize(b []byte, n int) []byte {
+	if cap(b) < n {
+		return make([]byte, n)
+	}
+	return b[:n]
+}
+
+// Creates a layer where every node is connected to every node in the left layer, using the global random source.
+func NewFullyConnectedLayer(left Layer, size int) *InferredLayer {
+	return NewFullyConnectedLayerWithRand(newRand(), left, size)
+}
+
+// Creates a layer where every node is connected to every node in the left layer, with initial edges that only depend
+// on seed, so that two layers created with the same seed and left size are identical.
+func NewFullyConnectedLayerSeeded(seed int64, left Layer, size int) *InferredLayer {
+	return NewFullyConnectedLayerWithRand(rand.New(rand.NewSource(seed)), left, size)
+}
+
+// Creates a layer where every node is connected to every node in the left layer, using r for the initial edges.
+func NewFullyConnectedLayerWithRand(r *rand.Rand, left Layer, size int) *InferredLayer {
+	checkSize(size)
+	l := &InferredLayer{
+		Nodes: make([]Node, size),
+		Left:  left,
+	}
+	leftSize := left.Size()
+	b := make([]byte, len(l.Nodes)*leftSize*2)
+	r.Read(b)
+	var bi int
+	for i := 0; i < len(l.Nodes); i++ {
+		edges := make([]Edge, leftSize)
+		for j := 0; j < leftSize; j++ {
+			edges[j].Index = j
+			edges[j].And = b[bi]
+			edges[j].Xor = b[bi+1]
+			bi += 2
+		}
+		l.Nodes[i].Inputs = edges
+	}
+	return l
+}
+
+// Panics if size is negative. Constructors that don't return errors call this so that a bad size fails with a clear
+// message rather than an index out of range.
+func checkSize(size int) {
+	if size < 0 {
+		panic(fmt.Sprintf("layer size %d is negative", size))
+	}
+}
+
+// Creates a layer where every node is connected to fanIn distinct random nodes in the left layer, using the global
+// random source.
+func NewSparseLayer(left Layer, size, fanIn int) (*InferredLayer, error) {
+	return NewSparseLayerWithRand(newRand(), left, size, fanIn)
+}
+
+// Creates a layer where every node is connected to fanIn distinct random nodes in the left layer, using r for the
+// connections and initial edges.
+func NewSparseLayerWithRand(r *rand.Rand, left Layer, size, fanIn int) (*InferredLayer, error) {
+	leftSize := left.Size()
+	if size < 0 {
+		return nil, fmt.Errorf("layer size %d is negative", size)
+	}
+	if fanIn < 0 || fanIn > leftSize {
+		return nil, fmt.Errorf("fan-in %d is out of range [0, %d]", fanIn, leftSize)
+	}
+	l := &InferredLayer{
+		Nodes: make([]Node, size),
+		Left:  left,
+	}
+	for i := range l.Nodes {
+		indices := r.Perm(leftSize)[:fanIn]
+		sort.Ints(indices)
+		edges := make([]Edge, fanIn)
+		for j, index := range indices {
+			edges[j] = randomEdge(r, index)
+		}
+		l.Nodes[i].Inputs = edges
+	}
+	return l, nil
+}
@@ -0,0 +1,197 @@
+package neural
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Evaluates a small hand-written network on fixed inputs and compares the outputs with values worked out by hand.
+func TestGetValues(t *testing.T) {
+	in := make(StaticLayer, 3)
+	hidden := &InferredLayer{
+		Left: in,
+		Nodes: []Node{
+			// in0 ^ in1&0f ^ 10
+			{Inputs: []Edge{
+				{Index: 0, And: 0xff},
+				{Index: 1, And: 0x0f, Xor: 0x10},
+			}},
+			// 01 ^ (in2 | f0)
+			{Bias: 0x01, Inputs: []Edge{
+				{Index: 2, And: 0xf0, Op: OpOr},
+			}},
+		},
+	}
+	out := &InferredLayer{
+		Left: hidden,
+		Nodes: []Node{
+			// Three edges into one node, two of them from the same input: h0 ^ h1 ^ ^(h0&01)^ff == h0 ^ h1 ^ h0&01
+			{Inputs: []Edge{
+				{Index: 0, And: 0xff},
+				{Index: 1, And: 0xff},
+				{Index: 0, And: 0x01, Xor: 0xff, Op: OpNand},
+			}},
+		},
+	}
+
+	cases := []struct {
+		name                  string
+		input, hidden, output []byte
+	}{
+		{"zeros", []byte{0x00, 0x00, 0x00}, []byte{0x10, 0xf1}, []byte{0xe1}},
+		{"mixed", []byte{0x01, 0x23, 0x45}, []byte{0x12, 0xf4}, []byte{0xe6}},
+		{"ones", []byte{0xff, 0xff, 0xff}, []byte{0xe0, 0xfe}, []byte{0x1e}},
+		// The same input again, after the cache has been filled with other values.
+		{"zeros again", []byte{0x00, 0x00, 0x00}, []byte{0x10, 0xf1}, []byte{0xe1}},
+	}
+	for _, c := range cases {
+		if err := in.Set(c.input); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.GetValues(); !bytes.Equal(got, c.output) {
+			t.Errorf("%s: output %x, want %x", c.name, got, c.output)
+		}
+		if got := hidden.GetValues(); !bytes.Equal(got, c.hidden) {
+			t.Errorf("%s: hidden %x, want %x", c.name, got, c.hidden)
+		}
+	}
+}
+
+func TestMutateRarity(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	l := NewFullyConnectedLayerWithRand(r, make(StaticLayer, 64), 64)
+	// Returns the number of edges that a mutation with the given rarity changed, summed over several mutations.
+	changed := func(rarity int) int {
+		n := 0
+		for k := 0; k < 10; k++ {
+			c := l.CopyInferred()
+			c.Mutate(r, rarity)
+			for i, node := range c.Nodes {
+				for j, e := range node.Inputs {
+					if e != l.Nodes[i].Inputs[j] {
+						n++
+					}
+				}
+			}
+		}
+		return n
+	}
+	prev := -1
+	for _, rarity := range []int{10000, 1000, 100, 10} {
+		n := changed(rarity)
+		if n <= prev {
+			t.Errorf("rarity %d changed %d edges, no more than the %d of a higher rarity", rarity, n, prev)
+		}
+		prev = n
+	}
+}
+
+func TestMutateStability(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	l := NewFullyConnectedLayerWithRand(r, make(StaticLayer, 64), 64)
+	for k := 0; k < 10; k++ {
+		l.Mutate(r, 10)
+	}
+	n := 0
+	for _, node := range l.Nodes {
+		for _, e := range node.Inputs {
+			if e.Stability != 0 {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		t.Error("mutations didn't change the Stability of any edge")
+	}
+}
+
+func TestCloneIndependent(t *testing.T) {
+	in := StaticLayer{1, 2, 3}
+	c := in.Clone()
+	in[0] = 9
+	if c[0] != 1 {
+		t.Errorf("changing the original changed the clone to %v", c)
+	}
+
+	l := NewFullyConnectedLayerWithRand(rand.New(rand.NewSource(1)), NewConcatLayer(in, in), 4)
+	want := l.GetValues()
+	d := DeepCopy(l)
+	in.Set([]byte{4, 5, 6})
+	if got := d.GetValues(); !bytes.Equal(got, want) {
+		t.Errorf("changing the original's input changed the deep copy's values from %v to %v", want, got)
+	}
+	dIn, err := inputOf(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = l.GetValues()
+	dIn.Set([]byte{7, 8, 9})
+	if got := l.GetValues(); !bytes.Equal(got, want) {
+		t.Errorf("changing the deep copy's input changed the original's values from %v to %v", want, got)
+	}
+}
+
+func TestSmallLayers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	one := NewFullyConnectedLayerWithRand(r, StaticLayer{1, 2, 3}, 1)
+	if got := one.GetValues(); len(got) != 1 {
+		t.Errorf("one-node layer has values %v", got)
+	}
+
+	// Nodes reading from an empty layer have no edges, so they output their biases.
+	empty := StaticLayer{}
+	l := NewFullyConnectedLayerWithRand(r, empty, 2)
+	l.Nodes[1].Bias = 7
+	if got := l.GetValues(); !bytes.Equal(got, []byte{0, 7}) {
+		t.Errorf("layer reading from an empty input has values %v, want [0 7]", got)
+	}
+	if got := NewFullyConnectedLayerWithRand(r, empty, 0).GetValues(); len(got) != 0 {
+		t.Errorf("empty layer has values %v", got)
+	}
+
+	score, err := TicTacToeFitness{Deterministic: true}.Evaluate(r, empty, nil)
+	if err != nil || score != 0 {
+		t.Errorf("TicTacToeFitness of an empty board is %d, %v, want 0, nil", score, err)
+	}
+	if _, err := (TicTacToeFitness{}).Evaluate(r, empty, []byte{1}); err == nil {
+		t.Error("TicTacToeFitness accepted an output longer than the empty board")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a negative layer size didn't panic")
+		}
+	}()
+	NewFullyConnectedLayerWithRand(r, empty, -1)
+}
+
+func TestCopyShared(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := NewFullyConnectedLayerWithRand(r, StaticLayer{1, 2, 3}, 4)
+	l := NewFullyConnectedLayerWithRand(r, NewConcatLayer(h, &ShiftLayer{Left: h, Shift: 1}), 3)
+	for name, c := range map[string]*InferredLayer{
+		"Copy":         l.Copy().(*InferredLayer),
+		"CopyInferred": l.CopyInferred(),
+		"DeepCopy":     DeepCopy(l).(*InferredLayer),
+	} {
+		concat := c.Left.(*ConcatLayer)
+		if concat.Layers[0] != concat.Layers[1].(*ShiftLayer).Left {
+			t.Errorf("%s: the hidden layer isn't shared in the copy", name)
+		}
+		if concat.Layers[0] == Layer(h) {
+			t.Errorf("%s: the hidden layer wasn't copied", name)
+		}
+		if ParamCount(c) != ParamCount(l) || !Equal(c, l) {
+			t.Errorf("%s: copy has %d edges, want %d", name, ParamCount(c), ParamCount(l))
+		}
+	}
+
+	// Equal tells a network that shares a layer from one that reads from two copies of it.
+	unshared := NewFullyConnectedLayerWithRand(r, NewConcatLayer(h, &ShiftLayer{Left: h.Copy(), Shift: 1}), 3)
+	unshared.Nodes = l.Nodes
+	if Equal(l, unshared) || Hash(l) == Hash(unshared) {
+		t.Error("network with a shared layer is equal to one with two copies of it")
+	}
+}
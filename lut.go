@@ -0,0 +1,123 @@
+package neural
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The largest number of bits that a LUTNode can index its table with.
+const LUTBits = 8
+
+// A layer of lookup tables, which can represent any function of up to LUTBits input bits per node, unlike the AND and
+// XOR of an InferredLayer's edges, while still costing a single table lookup per node to evaluate.
+type LUTLayer struct {
+	Nodes []LUTNode
+	Left  Layer
+}
+
+// A node that gathers single bits of the left values into an index and outputs the table entry at that index.
+type LUTNode struct {
+	// The bits that make up the index, from its least significant bit up. There are at most LUTBits of them, and only
+	// the first 1<<len(Inputs) entries of Table are used.
+	Inputs []BitInput `json:"inputs"`
+	Table  [256]byte  `json:"table"`
+}
+
+// A single bit of a value of the left layer.
+type BitInput struct {
+	Index int `json:"index"`
+	// The bit within the value, from 0 for the least significant bit to 7.
+	Bit byte `json:"bit"`
+}
+
+// Creates a layer where every node reads bits random bits of the left layer and has a random table, using r.
+func NewLUTLayerWithRand(r *rand.Rand, left Layer, size, bits int) (*LUTLayer, error) {
+	leftSize := left.Size()
+	if size < 0 {
+		return nil, fmt.Errorf("layer size %d is negative", size)
+	}
+	if bits < 0 || bits > LUTBits {
+		return nil, fmt.Errorf("number of bits %d is out of range [0, %d]", bits, LUTBits)
+	}
+	if leftSize == 0 && bits > 0 {
+		return nil, fmt.Errorf("left layer has no bits to read")
+	}
+	l := &LUTLayer{Nodes: make([]LUTNode, size), Left: left}
+	for i := range l.Nodes {
+		n := &l.Nodes[i]
+		n.Inputs = make([]BitInput, bits)
+		for j := range n.Inputs {
+			n.Inputs[j] = randomBitInput(r, leftSize)
+		}
+		r.Read(n.Table[:])
+	}
+	return l, nil
+}
+
+// Returns a random bit of a left layer of leftSize values.
+func randomBitInput(r *rand.Rand, leftSize int) BitInput {
+	return BitInput{Index: r.Intn(leftSize), Bit: byte(r.Intn(8))}
+}
+
+func (l *LUTLayer) Copy() Layer {
+	return &LUTLayer{Nodes: copyLUTNodes(l.Nodes), Left: l.Left.Copy()}
+}
+
+// Returns a copy of the nodes that doesn't share any inputs with them.
+func copyLUTNodes(nodes []LUTNode) []LUTNode {
+	c := make([]LUTNode, len(nodes))
+	for i, n := range nodes {
+		c[i] = n
+		c[i].Inputs = append([]BitInput(nil), n.Inputs...)
+	}
+	return c
+}
+
+func (l *LUTLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *LUTLayer) valuesInto(dst []byte) {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	l.lookup(lv, dst)
+}
+
+// Writes the table entry that each node's bits of the left values lv select into dst.
+func (l *LUTLayer) lookup(lv, dst []byte) {
+	for i := range l.Nodes {
+		n := &l.Nodes[i]
+		var index byte
+		for j, in := range n.Inputs {
+			index |= (lv[in.Index] >> in.Bit & 1) << uint(j)
+		}
+		dst[i] = n.Table[index]
+	}
+}
+
+// Flips a random bit of each used table entry, and moves each input to a random bit of the left layer, with a
+// probability of 1/rarity each, in this layer and all layers to the left of it.
+func (l *LUTLayer) Mutate(r *rand.Rand, rarity int) {
+	leftSize := l.Left.Size()
+	for i := range l.Nodes {
+		n := &l.Nodes[i]
+		for j := range n.Inputs {
+			if leftSize > 0 && r.Intn(rarity) == 0 {
+				n.Inputs[j] = randomBitInput(r, leftSize)
+			}
+		}
+		for j := 0; j < 1<<len(n.Inputs) && j < len(n.Table); j++ {
+			if r.Intn(rarity) == 0 {
+				n.Table[j] ^= 1 << uint(r.Intn(8))
+			}
+		}
+	}
+	mutateLeft(r, l.Left, rarity)
+}
+
+func (l *LUTLayer) Size() int {
+	return len(l.Nodes)
+}
@@ -0,0 +1,44 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// A layer that outputs the bitwise complement of every value of the left layer, so that networks can negate values
+// without spending edges on it.
+type NotLayer struct {
+	Left Layer
+}
+
+func (l *NotLayer) Copy() Layer {
+	return &NotLayer{Left: l.Left.Copy()}
+}
+
+func (l *NotLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *NotLayer) valuesInto(dst []byte) {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	complement(lv, dst)
+}
+
+// Writes the complement of the left values lv into dst.
+func complement(lv, dst []byte) {
+	for i, v := range lv {
+		dst[i] = ^v
+	}
+}
+
+// Mutates the layers to the left since the complement itself has nothing to train.
+func (l *NotLayer) Mutate(r *rand.Rand, rarity int) {
+	mutateLeft(r, l.Left, rarity)
+}
+
+func (l *NotLayer) Size() int {
+	return l.Left.Size()
+}
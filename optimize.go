@@ -0,0 +1,93 @@
+package neural
+
+// Removes the edges of this layer and all inferred layers to the left of it that don't depend on their input, e.g.
+// because the And mask is zero, and folds their constant contribution into the node's bias. The values of the network
+// are unchanged. Returns the number of edges removed.
+func Prune(l *InferredLayer) int {
+	removed := 0
+	for i := range l.Nodes {
+		n := &l.Nodes[i]
+		inputs := n.Inputs[:0]
+		for _, e := range n.Inputs {
+			if c, ok := e.constant(); ok {
+				n.Bias ^= c
+				removed++
+				continue
+			}
+			inputs = append(inputs, e)
+		}
+		n.Inputs = inputs
+	}
+	if il, ok := l.Left.(*InferredLayer); ok {
+		removed += Prune(il)
+	}
+	return removed
+}
+
+// Returns the value that the edge contributes regardless of its input, if it doesn't depend on the input.
+func (e Edge) constant() (byte, bool) {
+	mask, c := e.affine()
+	return c, mask == 0
+}
+
+// Returns the effect of the edge on its node as a mask and a constant, such that it contributes
+// input&mask ^ constant. Every operation can be written this way since each bit of the result only depends on the
+// same bit of the input, and only through XOR.
+func (e Edge) affine() (mask, constant byte) {
+	switch e.Op {
+	case OpOr:
+		// (v | a) == v&^a ^ a
+		return ^e.And, e.And ^ e.Xor
+	case OpNand:
+		return e.And, ^e.Xor
+	default:
+		return e.And, e.Xor
+	}
+}
+
+// Collapses the layer and its left layer into a single equivalent layer that reads from the left layer's left layer,
+// which it shares with l. Since every node computes an XOR of its inputs masked bit by bit (see Edge.affine), two
+// inferred layers always compose exactly, and the result has at most one edge per node to the left layer's left
+// layer. If Left isn't an *InferredLayer (e.g. a max-pool, which ORs its inputs, or a recurrent layer, which has
+// state) or has nodes with an activation or saturation, there's nothing to fuse and l itself is returned. The
+// activations and saturation of l's own nodes are kept. Note that the fused layer can have more edges than the
+// two layers had together.
+func Fuse(l *InferredLayer) *InferredLayer {
+	left, ok := l.Left.(*InferredLayer)
+	if !ok {
+		return l
+	}
+	for _, node := range left.Nodes {
+		if node.Activation != ActivationNone || node.Max != 0 {
+			return l
+		}
+	}
+	size := left.Left.Size()
+	fused := &InferredLayer{Nodes: make([]Node, len(l.Nodes)), Left: left.Left}
+	masks := make([]byte, size)
+	for i, node := range l.Nodes {
+		clear(masks)
+		bias := node.Bias
+		for _, e := range node.Inputs {
+			mask, c := e.affine()
+			bias ^= c
+			// The left node's value is its bias and constants XORed with its masked inputs, so masking it
+			// distributes over each of them.
+			ln := left.Nodes[e.Index]
+			bias ^= mask & ln.Bias
+			for _, le := range ln.Inputs {
+				lmask, lc := le.affine()
+				bias ^= mask & lc
+				masks[le.Index] ^= mask & lmask
+			}
+		}
+		fused.Nodes[i].Bias = bias
+		fused.Nodes[i].Activation, fused.Nodes[i].Threshold, fused.Nodes[i].Max = node.Activation, node.Threshold, node.Max
+		for k, mask := range masks {
+			if mask != 0 {
+				fused.Nodes[i].Inputs = append(fused.Nodes[i].Inputs, Edge{Index: k, And: mask})
+			}
+		}
+	}
+	return fused
+}
@@ -0,0 +1,167 @@
+package neural
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// A fixed number of bits packed 64 to a word. Copies of a bitset share its bits, like slices share their elements.
+type Bitset struct {
+	words []uint64
+	n     int
+}
+
+// Creates a bitset of n bits that are all unset.
+func NewBitset(n int) Bitset {
+	return Bitset{words: make([]uint64, (n+63)/64), n: n}
+}
+
+// Creates a bitset with one bit per value, set wherever the value is non-zero.
+func PackBytes(values []byte) Bitset {
+	b := NewBitset(len(values))
+	for i, v := range values {
+		b.Set(i, v != 0)
+	}
+	return b
+}
+
+// Returns one byte per bit, 1 where the bit is set and 0 elsewhere.
+func (b Bitset) Bytes() []byte {
+	v := make([]byte, b.n)
+	for i := range v {
+		if b.Get(i) {
+			v[i] = 1
+		}
+	}
+	return v
+}
+
+func (b Bitset) Len() int {
+	return b.n
+}
+
+func (b Bitset) Get(i int) bool {
+	return b.words[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (b Bitset) Set(i int, v bool) {
+	if v {
+		b.words[i/64] |= 1 << uint(i%64)
+	} else {
+		b.words[i/64] &^= 1 << uint(i%64)
+	}
+}
+
+// Returns a copy of the bitset that doesn't share its bits.
+func (b Bitset) Clone() Bitset {
+	return Bitset{words: append([]uint64(nil), b.words...), n: b.n}
+}
+
+// Like Layer, but with one bit per node. Packed layers use an eighth of the memory of byte-based layers for their
+// values and masks, which keeps wide networks in cache.
+type PackedLayer interface {
+	Copy() PackedLayer
+	GetBits() Bitset
+	Size() int
+}
+
+// A node of a packed layer. Its value is the parity of the left bits selected by Mask, XORed with Bias, which is what a
+// byte-based node computes for each bit when its edges select single bits.
+type PackedNode struct {
+	Mask Bitset
+	Bias bool
+}
+
+// Like InferredLayer, but with one bit per node.
+type PackedInferredLayer struct {
+	Nodes []PackedNode
+	Left  PackedLayer
+}
+
+func (l *PackedInferredLayer) Copy() PackedLayer {
+	nodes := make([]PackedNode, len(l.Nodes))
+	for i, n := range l.Nodes {
+		nodes[i] = PackedNode{Mask: n.Mask.Clone(), Bias: n.Bias}
+	}
+	return &PackedInferredLayer{Nodes: nodes, Left: l.Left.Copy()}
+}
+
+func (l *PackedInferredLayer) GetBits() Bitset {
+	lv := l.Left.GetBits()
+	v := NewBitset(len(l.Nodes))
+	for i, node := range l.Nodes {
+		var ones int
+		for j, w := range node.Mask.words {
+			ones += bits.OnesCount64(w & lv.words[j])
+		}
+		v.Set(i, ones%2 == 1 != node.Bias)
+	}
+	return v
+}
+
+// Flips each mask bit and bias with a probability of 1/rarity, in this layer and all packed layers to the left of it.
+func (l *PackedInferredLayer) Mutate(r *rand.Rand, rarity int) {
+	for i := range l.Nodes {
+		if r.Intn(rarity) == 0 {
+			l.Nodes[i].Bias = !l.Nodes[i].Bias
+		}
+		mask := l.Nodes[i].Mask
+		for j := 0; j < mask.n; j++ {
+			if r.Intn(rarity) == 0 {
+				mask.Set(j, !mask.Get(j))
+			}
+		}
+	}
+	if m, ok := l.Left.(interface{ Mutate(*rand.Rand, int) }); ok {
+		m.Mutate(r, rarity)
+	}
+}
+
+func (l *PackedInferredLayer) Size() int {
+	return len(l.Nodes)
+}
+
+// Like StaticLayer, but with one bit per node.
+type PackedStaticLayer struct {
+	Bitset
+}
+
+// Creates a packed input layer of n bits that are all unset.
+func NewPackedStaticLayer(n int) PackedStaticLayer {
+	return PackedStaticLayer{NewBitset(n)}
+}
+
+// Returns the layer itself, like StaticLayer.Copy.
+func (l PackedStaticLayer) Copy() PackedLayer {
+	return l
+}
+
+func (l PackedStaticLayer) GetBits() Bitset {
+	return l.Bitset
+}
+
+func (l PackedStaticLayer) Size() int {
+	return l.n
+}
+
+// Like NewFullyConnectedLayerWithRand, but for packed layers: every node selects a random half of the left bits.
+func NewPackedFullyConnectedLayer(r *rand.Rand, left PackedLayer, size int) *PackedInferredLayer {
+	checkSize(size)
+	l := &PackedInferredLayer{
+		Nodes: make([]PackedNode, size),
+		Left:  left,
+	}
+	leftSize := left.Size()
+	for i := range l.Nodes {
+		mask := NewBitset(leftSize)
+		for j := range mask.words {
+			mask.words[j] = r.Uint64()
+		}
+		// Bits past the end must stay unset so they never select anything.
+		if rem := leftSize % 64; rem != 0 {
+			mask.words[len(mask.words)-1] &= 1<<uint(rem) - 1
+		}
+		l.Nodes[i] = PackedNode{Mask: mask, Bias: r.Intn(2) == 1}
+	}
+	return l
+}
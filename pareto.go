@@ -0,0 +1,143 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Like Fitness, but scores the output on several objectives at once, all of which are maximized. Every call in a
+// generation must return the same number of scores; networks that get a different number than most of the population
+// are treated as if Evaluate had returned an error.
+type MultiFitness interface {
+	Evaluate(r *rand.Rand, input StaticLayer, output []byte) ([]int, error)
+}
+
+// A network on the Pareto front, i.e., one that no other network in its generation beats on every objective.
+type ParetoNetwork struct {
+	Network ScoredLayer
+	// The network's scores for each objective, summed over all episodes.
+	Objectives []int
+}
+
+// Reports whether a is at least as good as b on every objective and better on at least one.
+func dominates(a, b []int) bool {
+	better := false
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+		if a[i] > b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// Marks the networks whose number of objectives differs from the most common one among the networks that haven't failed
+// as failed, so that every network that gets ranked has the same number. Ties go to the number seen first.
+func failMismatched(objectives [][]int, failed []bool) {
+	counts := make(map[int]int)
+	common := -1
+	for i, o := range objectives {
+		if failed[i] {
+			continue
+		}
+		counts[len(o)]++
+		if common < 0 || counts[len(o)] > counts[common] {
+			common = len(o)
+		}
+	}
+	for i, o := range objectives {
+		if len(o) != common {
+			failed[i] = true
+		}
+	}
+}
+
+// Ranks the networks by their objectives like NSGA-II: first by which non-dominated front they belong to, and within a
+// front by crowding distance, so that networks at the extremes or in sparse regions of the front come first. The rank
+// is stored in Score, counting down from len(pop) for the best network, so that the rest of the trainer can treat it
+// like any other score. Failed networks are left out. Returns the first front.
+func rankPareto(pop []ScoredLayer, objectives [][]int, failed []bool) []ParetoNetwork {
+	var fronts [][]int
+	// The networks each network dominates, and the number of networks dominating it.
+	dominated := make([][]int, len(pop))
+	count := make([]int, len(pop))
+	var front []int
+	for i := range pop {
+		if failed[i] {
+			continue
+		}
+		for j := range pop {
+			if failed[j] || i == j {
+				continue
+			}
+			if dominates(objectives[i], objectives[j]) {
+				dominated[i] = append(dominated[i], j)
+			} else if dominates(objectives[j], objectives[i]) {
+				count[i]++
+			}
+		}
+		if count[i] == 0 {
+			front = append(front, i)
+		}
+	}
+	for len(front) > 0 {
+		fronts = append(fronts, front)
+		var next []int
+		for _, i := range front {
+			for _, j := range dominated[i] {
+				if count[j]--; count[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		front = next
+	}
+	score := len(pop)
+	for _, front := range fronts {
+		distance := crowding(front, objectives)
+		sort.SliceStable(front, func(a, b int) bool {
+			return distance[front[a]] > distance[front[b]]
+		})
+		for _, i := range front {
+			pop[i].Score = score
+			score--
+		}
+	}
+	if len(fronts) == 0 {
+		return nil
+	}
+	first := make([]ParetoNetwork, len(fronts[0]))
+	for k, i := range fronts[0] {
+		first[k] = ParetoNetwork{Network: pop[i], Objectives: objectives[i]}
+	}
+	return first
+}
+
+// Returns the crowding distance of each network in a front, keyed by its index in the population: the sum over all
+// objectives of the normalized distance between its neighbors on that objective. The networks at either end of an
+// objective get an infinite distance so that they're always kept.
+func crowding(front []int, objectives [][]int) map[int]float64 {
+	distance := make(map[int]float64, len(front))
+	if len(front) == 0 {
+		return distance
+	}
+	sorted := append([]int(nil), front...)
+	for m := range objectives[front[0]] {
+		sort.Slice(sorted, func(a, b int) bool {
+			return objectives[sorted[a]][m] < objectives[sorted[b]][m]
+		})
+		lo, hi := objectives[sorted[0]][m], objectives[sorted[len(sorted)-1]][m]
+		distance[sorted[0]] = math.Inf(1)
+		distance[sorted[len(sorted)-1]] = math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for k := 1; k < len(sorted)-1; k++ {
+			distance[sorted[k]] += float64(objectives[sorted[k+1]][m]-objectives[sorted[k-1]][m]) / float64(hi-lo)
+		}
+	}
+	return distance
+}
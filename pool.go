@@ -0,0 +1,54 @@
+package neural
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// A layer that downsamples the left layer by ORing each window of consecutive values together, i.e., taking the
+// maximum of every bit in the window. If the left layer's size isn't a multiple of the window size, the last window is
+// smaller.
+type MaxPoolLayer struct {
+	Left   Layer
+	Window int
+}
+
+func NewMaxPoolLayer(left Layer, window int) (*MaxPoolLayer, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("window size %d must be at least 1", window)
+	}
+	return &MaxPoolLayer{Left: left, Window: window}, nil
+}
+
+func (l *MaxPoolLayer) Copy() Layer {
+	return &MaxPoolLayer{Left: l.Left.Copy(), Window: l.Window}
+}
+
+func (l *MaxPoolLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but ORs the values into dst, which must be zeroed.
+func (l *MaxPoolLayer) valuesInto(dst []byte) {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	l.pool(lv, dst)
+}
+
+// ORs each window of the left values lv into dst, which must be zeroed.
+func (l *MaxPoolLayer) pool(lv, dst []byte) {
+	for i, v := range lv {
+		dst[i/l.Window] |= v
+	}
+}
+
+// Mutates the layers to the left since the pooling itself has nothing to train.
+func (l *MaxPoolLayer) Mutate(r *rand.Rand, rarity int) {
+	mutateLeft(r, l.Left, rarity)
+}
+
+func (l *MaxPoolLayer) Size() int {
+	return (l.Left.Size() + l.Window - 1) / l.Window
+}
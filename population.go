@@ -0,0 +1,103 @@
+package neural
+
+import (
+	"fmt"
+	"io"
+)
+
+// Writes a population to the file at path, replacing it atomically. Scores are saved along with the networks, but
+// note that the trainer scores every network again in the next generation (except preserved elites), so they're
+// mostly informational.
+func SavePopulation(path string, pop []ScoredLayer) error {
+	return writeFile(path, func(w io.Writer) error {
+		return encodePopulation(w, pop)
+	})
+}
+
+// Like SavePopulation, but compresses the file with gzip. LoadPopulation reads both kinds of files.
+func SavePopulationCompressed(path string, pop []ScoredLayer) error {
+	return writeFile(path, gzipped(func(w io.Writer) error {
+		return encodePopulation(w, pop)
+	}))
+}
+
+// Writes a population as one record per network. Layers shared between networks, like their input, are written once.
+func encodePopulation(w io.Writer, pop []ScoredLayer) error {
+	roots := make([]Layer, len(pop))
+	for i, p := range pop {
+		roots[i] = p
+	}
+	e := newLayerEncoder(roots...)
+	recs := make([]*layerRecord, len(pop))
+	for i, p := range pop {
+		var err error
+		if recs[i], err = e.encode(p); err != nil {
+			return fmt.Errorf("network %d: %w", i, err)
+		}
+	}
+	return encodeVersioned(w, recs)
+}
+
+// Reads a population previously written with SavePopulation or SavePopulationCompressed, including the scores. All networks read from one shared
+// input layer, like they did when they were saved.
+func LoadPopulation(path string) ([]ScoredLayer, error) {
+	var pop []ScoredLayer
+	if err := readFile(path, func(r io.Reader) error {
+		var recs []*layerRecord
+		version, err := decodeVersioned(r, &pop, &recs)
+		if err != nil || version < 3 {
+			return err
+		}
+		pop, err = decodePopulation(recs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if len(pop) == 0 {
+		return pop, nil
+	}
+	// Before version 3, every network was decoded with its own copy of the input.
+	in, err := inputOf(pop[0].InferredLayer)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pop {
+		if err := setInput(pop[i].InferredLayer, in); err != nil {
+			return nil, fmt.Errorf("network %d: %w", i, err)
+		}
+	}
+	return pop, nil
+}
+
+// Reads the networks that encodePopulation wrote.
+func decodePopulation(recs []*layerRecord) ([]ScoredLayer, error) {
+	d := newLayerDecoder()
+	pop := make([]ScoredLayer, len(recs))
+	for i, rec := range recs {
+		l, err := d.decode(rec)
+		if err != nil {
+			return nil, fmt.Errorf("network %d: %w", i, err)
+		}
+		s, ok := l.(*ScoredLayer)
+		if !ok {
+			return nil, fmt.Errorf("network %d is a %T, not a scored layer", i, l)
+		}
+		pop[i] = *s
+	}
+	return pop, nil
+}
+
+// Makes a network read from in instead of its current input layer, which must be the only one and have the same size.
+func setInput(l Layer, in StaticLayer) error {
+	old, err := inputOf(l)
+	if err != nil {
+		return err
+	}
+	if err := CheckLengths(old, in); err != nil {
+		return err
+	}
+	mapInputs(l, func(StaticLayer) StaticLayer {
+		return in
+	})
+	return nil
+}
@@ -0,0 +1,29 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// Creates a random source seeded from the global one, for functions that aren't given a source.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(rand.Int63()))
+}
+
+// A tiny random source (SplitMix64) that is cheap enough to create one per network per generation.
+type splitMix64 uint64
+
+func (s *splitMix64) Seed(seed int64) {
+	*s = splitMix64(seed)
+}
+
+func (s *splitMix64) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
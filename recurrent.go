@@ -0,0 +1,90 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// A layer whose nodes read from both the left layer and the layer's own output from the previous evaluation. Edge
+// indices below Left.Size() refer to the left layer and the rest refer to State, so every call to GetValues advances
+// the layer by one step.
+type RecurrentLayer struct {
+	Nodes []Node
+	Left  Layer
+	// The output of the previous evaluation (all zeroes initially). Its length always equals the number of nodes.
+	State []byte
+
+	// Scratch buffer holding the left values followed by the state.
+	in []byte
+}
+
+// Creates a recurrent layer where every node is connected to every node in the left layer and every node in the state,
+// using the global random source.
+func NewRecurrentLayer(left Layer, size int) *RecurrentLayer {
+	return NewRecurrentLayerWithRand(newRand(), left, size)
+}
+
+// Creates a recurrent layer where every node is connected to every node in the left layer and every node in the state,
+// using r for the initial edges.
+func NewRecurrentLayerWithRand(r *rand.Rand, left Layer, size int) *RecurrentLayer {
+	checkSize(size)
+	l := &RecurrentLayer{
+		Nodes: make([]Node, size),
+		Left:  left,
+		State: make([]byte, size),
+	}
+	inSize := left.Size() + size
+	for i := range l.Nodes {
+		edges := make([]Edge, inSize)
+		for j := range edges {
+			edges[j] = randomEdge(r, j)
+		}
+		l.Nodes[i].Inputs = edges
+	}
+	return l
+}
+
+func (l *RecurrentLayer) Copy() Layer {
+	return &RecurrentLayer{
+		Nodes: copyNodes(l.Nodes),
+		Left:  l.Left.Copy(),
+		State: append([]byte(nil), l.State...),
+	}
+}
+
+func (l *RecurrentLayer) GetValues() []byte {
+	v := make([]byte, len(l.Nodes))
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *RecurrentLayer) valuesInto(dst []byte) {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	l.step(lv, dst)
+}
+
+// Advances the layer by one step for the left values lv, writing the new state into dst as well as State.
+func (l *RecurrentLayer) step(lv, dst []byte) {
+	l.State = resize(l.State, len(l.Nodes))
+	l.in = append(append(l.in[:0], lv...), l.State...)
+	computeNodes(l.Nodes, l.in, dst)
+	copy(l.State, dst)
+}
+
+// Randomly flips bits in the edges of this layer and all layers to the left of it, like InferredLayer.Mutate.
+func (l *RecurrentLayer) Mutate(r *rand.Rand, rarity int) {
+	mutateWeights(r, l.Nodes, rarity)
+	mutateLeft(r, l.Left, rarity)
+}
+
+// Clears the state so that the next evaluation behaves like the first one.
+func (l *RecurrentLayer) ResetState() {
+	for i := range l.State {
+		l.State[i] = 0
+	}
+}
+
+func (l *RecurrentLayer) Size() int {
+	return len(l.Nodes)
+}
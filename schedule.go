@@ -0,0 +1,74 @@
+package neural
+
+// Adjusts the rarity that offspring are mutated with as training progresses.
+type MutationSchedule interface {
+	// Called once per generation, before any offspring are made, with whether the best score improved by more than
+	// MinDelta on the best score so far.
+	Update(improved bool)
+	// Returns the rarity to mutate an offspring with in place of the rarity configured for it.
+	Rarity(rarity int) int
+}
+
+// Always mutates with the configured rarity. This is the default.
+type ConstantSchedule struct{}
+
+func (ConstantSchedule) Update(improved bool) {}
+
+func (ConstantSchedule) Rarity(rarity int) int {
+	return rarity
+}
+
+// Scales the configured rarity by how often the best score has improved recently: if more than a fifth of the last
+// Window generations improved it, mutations become rarer by Factor, and otherwise they become more frequent by Factor,
+// so that a stalled run explores more and an improving one exploits what it found.
+type AdaptiveSchedule struct {
+	// The number of recent generations the success rate is measured over. Defaults to 10.
+	Window int
+	// How much the rarity is scaled by every generation. Defaults to 1.2.
+	Factor float64
+
+	scale   float64
+	history []bool
+}
+
+// The scale is bounded so that a long stall or winning streak can be undone in a reasonable number of generations.
+const (
+	minScale = 1.0 / 64
+	maxScale = 64.0
+)
+
+func (s *AdaptiveSchedule) Update(improved bool) {
+	window := s.Window
+	if window <= 0 {
+		window = 10
+	}
+	factor := s.Factor
+	if factor <= 1 {
+		factor = 1.2
+	}
+	if s.scale == 0 {
+		s.scale = 1
+	}
+	s.history = append(s.history, improved)
+	if len(s.history) > window {
+		s.history = s.history[len(s.history)-window:]
+	}
+	successes := 0
+	for _, ok := range s.history {
+		if ok {
+			successes++
+		}
+	}
+	if successes*5 > len(s.history) {
+		s.scale = min(s.scale*factor, maxScale)
+	} else {
+		s.scale = max(s.scale/factor, minScale)
+	}
+}
+
+func (s *AdaptiveSchedule) Rarity(rarity int) int {
+	if s.scale == 0 {
+		return rarity
+	}
+	return max(1, int(float64(rarity)*s.scale))
+}
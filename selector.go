@@ -0,0 +1,76 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// Chooses the parent of an offspring. The population is sorted by descending score, and Select returns the index of
+// the chosen network.
+type Selector interface {
+	Select(r *rand.Rand, pop []ScoredLayer) int
+}
+
+// Picks the best of K networks chosen uniformly at random (with replacement).
+type TournamentSelector struct {
+	K int
+}
+
+func (s TournamentSelector) Select(r *rand.Rand, pop []ScoredLayer) int {
+	best := r.Intn(len(pop))
+	for i := 1; i < s.K; i++ {
+		// The population is sorted, so a lower index is at least as good.
+		if j := r.Intn(len(pop)); j < best {
+			best = j
+		}
+	}
+	return best
+}
+
+// Picks one of the N highest scoring networks uniformly at random.
+type TruncationSelector struct {
+	N int
+}
+
+func (s TruncationSelector) Select(r *rand.Rand, pop []ScoredLayer) int {
+	if s.N <= 0 || s.N > len(pop) {
+		return r.Intn(len(pop))
+	}
+	return r.Intn(s.N)
+}
+
+// Picks networks with a probability proportional to their score (fitness-proportional or roulette wheel selection).
+// Since scores can be negative, every score is offset by the lowest score in the population minus one, so that the
+// worst network has a weight of 1 and the weights keep the same differences as the scores. Networks with FailedScore
+// have a weight of 0 and are left out when finding the lowest score.
+type RouletteSelector struct{}
+
+func (RouletteSelector) Select(r *rand.Rand, pop []ScoredLayer) int {
+	lowest := 0
+	found := false
+	for _, p := range pop {
+		if p.Score != FailedScore && (!found || p.Score < lowest) {
+			lowest, found = p.Score, true
+		}
+	}
+	if !found {
+		return r.Intn(len(pop))
+	}
+	var total int64
+	for _, p := range pop {
+		total += rouletteWeight(p.Score, lowest)
+	}
+	x := r.Int63n(total)
+	for i, p := range pop {
+		if x -= rouletteWeight(p.Score, lowest); x < 0 {
+			return i
+		}
+	}
+	return len(pop) - 1
+}
+
+func rouletteWeight(score, lowest int) int64 {
+	if score == FailedScore {
+		return 0
+	}
+	return int64(score) - int64(lowest) + 1
+}
@@ -0,0 +1,38 @@
+package neural
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRouletteSelector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pops := [][]int{
+		{1, 5, 20},
+		{-30, -10, 0, 10},
+		{-5, FailedScore, 5, FailedScore, 15},
+	}
+	for _, scores := range pops {
+		pop := make([]ScoredLayer, len(scores))
+		for i, s := range scores {
+			pop[i].Score = s
+		}
+		counts := make([]int, len(pop))
+		for k := 0; k < 10000; k++ {
+			counts[RouletteSelector{}.Select(r, pop)]++
+		}
+		prev := -1
+		for i, s := range scores {
+			if s == FailedScore {
+				if counts[i] != 0 {
+					t.Errorf("%v: failed network %d was picked %d times", scores, i, counts[i])
+				}
+				continue
+			}
+			if counts[i] <= prev {
+				t.Errorf("%v: score %d was picked %d times, no more than a lower score", scores, s, counts[i])
+			}
+			prev = counts[i]
+		}
+	}
+}
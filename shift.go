@@ -0,0 +1,54 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// A layer that outputs the values of the left layer rotated by Shift positions, so that value i is the left layer's
+// value (i+Shift) mod Size(). Concatenated with the left layer itself, it gives nodes access to neighboring values,
+// e.g. the next square of a board, through edges with the same index as the original. Shift can be negative.
+type ShiftLayer struct {
+	Left  Layer
+	Shift int
+}
+
+func (l *ShiftLayer) Copy() Layer {
+	return &ShiftLayer{Left: l.Left.Copy(), Shift: l.Shift}
+}
+
+func (l *ShiftLayer) GetValues() []byte {
+	v := make([]byte, l.Size())
+	l.valuesInto(v)
+	return v
+}
+
+// Like GetValues, but writes the values into dst.
+func (l *ShiftLayer) valuesInto(dst []byte) {
+	lv, buf := leftValues(l.Left)
+	defer putBuffer(buf)
+	l.rotate(lv, dst)
+}
+
+// Writes the left values lv, rotated by Shift, into dst.
+func (l *ShiftLayer) rotate(lv, dst []byte) {
+	if len(lv) == 0 {
+		return
+	}
+	k := l.offset(len(lv))
+	n := copy(dst, lv[k:])
+	copy(dst[n:], lv[:k])
+}
+
+// Returns the index of the left value that the first value is taken from, for a left layer of size n > 0.
+func (l *ShiftLayer) offset(n int) int {
+	return (l.Shift%n + n) % n
+}
+
+// Mutates the layers to the left since the rotation itself has nothing to train.
+func (l *ShiftLayer) Mutate(r *rand.Rand, rarity int) {
+	mutateLeft(r, l.Left, rarity)
+}
+
+func (l *ShiftLayer) Size() int {
+	return l.Left.Size()
+}
@@ -0,0 +1,24 @@
+package neural
+
+// Like GetValues, but for networks too wide to keep the values of every layer in memory at once. The chain of inferred
+// layers ending at l (see Genome) is evaluated bottom-up with just two buffers, which hold the values of the layer
+// being computed and of the layer it reads from, so the memory needed is bounded by the two widest adjacent layers
+// rather than the sum over all of them. The caches of the layers are neither used nor filled, so layers that GetValues
+// evaluated before still hold on to theirs. Whatever the lowest inferred layer reads from is evaluated as usual, and a
+// layer that isn't inferred is evaluated with GetValues.
+func GetValuesStreaming(l Layer) []byte {
+	il, ok := unwrap(l).(*InferredLayer)
+	if !ok {
+		return l.GetValues()
+	}
+	chain := inferredChain(il)
+	in := valuesOf(chain[0].Left)
+	// The buffers alternate between being read and written, and only the last one written is returned.
+	var bufs [2][]byte
+	for i, layer := range chain {
+		out := resize(bufs[i%2], len(layer.Nodes))
+		computeNodes(layer.Nodes, in, out)
+		bufs[i%2], in = out, out
+	}
+	return in
+}
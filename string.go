@@ -0,0 +1,105 @@
+package neural
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The number of edges included when printing a layer.
+const stringEdges = 3
+
+func (e Edge) String() string {
+	return fmt.Sprintf("[%d %s]", e.Index, e.masks())
+}
+
+// Formats the operation and masks of an edge as e.g. "&1f ^a0".
+func (e Edge) masks() string {
+	return fmt.Sprintf("%s%02x ^%02x", e.Op, e.And, e.Xor)
+}
+
+func (o Op) String() string {
+	switch o {
+	case OpAnd:
+		return "&"
+	case OpOr:
+		return "|"
+	case OpNand:
+		return "~&"
+	default:
+		return fmt.Sprintf("op%d", byte(o))
+	}
+}
+
+// Prints the layer's size and first few edges, followed by the layers to the left of it.
+func (l *InferredLayer) String() string {
+	return nodesString("", l.Nodes) + " <- " + fmt.Sprint(l.Left)
+}
+
+func (l ScoredLayer) String() string {
+	return fmt.Sprintf("(score %d) %v", l.Score, l.InferredLayer)
+}
+
+func (l StaticLayer) String() string {
+	return fmt.Sprintf("input%v", []byte(l))
+}
+
+func (l *RecurrentLayer) String() string {
+	return nodesString("recurrent ", l.Nodes) + " <- " + fmt.Sprint(l.Left)
+}
+
+func (l *MaxPoolLayer) String() string {
+	return fmt.Sprintf("maxpool %d/%d <- %v", l.Size(), l.Window, l.Left)
+}
+
+func (l *NotLayer) String() string {
+	return fmt.Sprintf("not %d <- %v", l.Size(), l.Left)
+}
+
+func (l *ShiftLayer) String() string {
+	return fmt.Sprintf("shift %d by %d <- %v", l.Size(), l.Shift, l.Left)
+}
+
+func (l *LUTLayer) String() string {
+	var bits int
+	for _, n := range l.Nodes {
+		bits += len(n.Inputs)
+	}
+	return fmt.Sprintf("lut %d nodes, %d bits <- %v", len(l.Nodes), bits, l.Left)
+}
+
+func (l *DAGLayer) String() string {
+	lefts := make([]string, len(l.Lefts))
+	for i, left := range l.Lefts {
+		lefts[i] = fmt.Sprint(left)
+	}
+	return nodesString("dag ", l.Nodes) + " <- (" + strings.Join(lefts, ", ") + ")"
+}
+
+func (l *ConcatLayer) String() string {
+	layers := make([]string, len(l.Layers))
+	for i, c := range l.Layers {
+		layers[i] = fmt.Sprint(c)
+	}
+	return "concat(" + strings.Join(layers, ", ") + ")"
+}
+
+// Formats a layer's nodes as e.g. "9 nodes, 81 edges [0 &1f ^a0] [1 &3c ^00] [2 &ff ^12]…".
+func nodesString(prefix string, nodes []Node) string {
+	var b strings.Builder
+	var edges, sampled int
+	for _, n := range nodes {
+		edges += len(n.Inputs)
+	}
+	fmt.Fprintf(&b, "%s%d nodes, %d edges", prefix, len(nodes), edges)
+	for _, n := range nodes {
+		for _, e := range n.Inputs {
+			if sampled == stringEdges {
+				b.WriteString("…")
+				return b.String()
+			}
+			b.WriteString(" " + e.String())
+			sampled++
+		}
+	}
+	return b.String()
+}
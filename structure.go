@@ -0,0 +1,118 @@
+package neural
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Randomly changes the connectivity of this layer and all inferred layers to the left of it. With a probability of
+// 1/rarity each, a node loses a random edge and gains an edge to a random node in the left layer. A node that loses
+// all its edges outputs its bias, after its activation. Frozen layers are skipped.
+func (l *InferredLayer) MutateEdges(r *rand.Rand, rarity int) {
+	if !l.Frozen {
+		l.Invalidate()
+		leftSize := l.Left.Size()
+		for i := range l.Nodes {
+			n := &l.Nodes[i]
+			if len(n.Inputs) > 0 && r.Intn(rarity) == 0 {
+				k := r.Intn(len(n.Inputs))
+				n.Inputs = append(n.Inputs[:k], n.Inputs[k+1:]...)
+			}
+			if leftSize > 0 && r.Intn(rarity) == 0 {
+				n.Inputs = insertEdge(n.Inputs, randomEdge(r, r.Intn(leftSize)))
+			}
+		}
+	}
+	if il, ok := l.Left.(*InferredLayer); ok {
+		il.MutateEdges(r, rarity)
+	}
+}
+
+// Inserts e into edges sorted by Index, after any edges with the same index.
+func insertEdge(edges []Edge, e Edge) []Edge {
+	k := sort.Search(len(edges), func(i int) bool { return edges[i].Index > e.Index })
+	edges = append(edges, Edge{})
+	copy(edges[k+1:], edges[k:])
+	edges[k] = e
+	return edges
+}
+
+// Sorts the edges of every node in the network by Index, keeping edges to the same index in their order, which is how
+// the constructors and mutations keep them. This is only needed for nodes that were built or edited by hand, so that
+// e.g. Diff and Crossover line up their edges. The values of the network don't change.
+func SortEdges(l Layer) {
+	for _, l := range Layers(l) {
+		var nodes []Node
+		switch l := l.(type) {
+		case *InferredLayer:
+			nodes = l.Nodes
+		case *RecurrentLayer:
+			nodes = l.Nodes
+		case *DAGLayer:
+			nodes = l.Nodes
+		}
+		for _, n := range nodes {
+			sort.SliceStable(n.Inputs, func(i, j int) bool { return n.Inputs[i].Index < n.Inputs[j].Index })
+		}
+	}
+}
+
+// Creates an edge to the node at index with random And and Xor masks.
+func randomEdge(r *rand.Rand, index int) Edge {
+	v := r.Uint64()
+	return Edge{Index: index, And: byte(v), Xor: byte(v >> 8)}
+}
+
+// Randomly grows and shrinks the inferred layers to the left of this one. The size of this layer is never changed
+// since it's the output of the network. For each layer to the left, with a probability of 1/rarity each, a random node
+// is removed and a new node is added. Layers are mutated top-down so that edges referring to a removed node can be
+// dropped and the remaining indices shifted in the layer consuming it, which keeps the network valid. A layer is only
+// resized if neither it nor its consumer is frozen.
+func (l *InferredLayer) MutateNodes(r *rand.Rand, rarity int) {
+	left, ok := l.Left.(*InferredLayer)
+	if !ok {
+		return
+	}
+	// Resizing the left layer also changes the edges of this layer.
+	if l.Frozen || left.Frozen {
+		left.MutateNodes(r, rarity)
+		return
+	}
+	left.Invalidate()
+	if len(left.Nodes) > 1 && r.Intn(rarity) == 0 {
+		k := r.Intn(len(left.Nodes))
+		left.Nodes = append(left.Nodes[:k], left.Nodes[k+1:]...)
+		l.removeInputs(k)
+	}
+	if leftSize := left.Left.Size(); leftSize > 0 && r.Intn(rarity) == 0 {
+		var n Node
+		for i := 0; i < 3; i++ {
+			n.Inputs = insertEdge(n.Inputs, randomEdge(r, r.Intn(leftSize)))
+		}
+		left.Nodes = append(left.Nodes, n)
+		// Connect the new node to a random consumer so that it isn't dead from the start.
+		if len(l.Nodes) > 0 {
+			c := &l.Nodes[r.Intn(len(l.Nodes))]
+			c.Inputs = insertEdge(c.Inputs, randomEdge(r, len(left.Nodes)-1))
+		}
+	}
+	l.Invalidate()
+	left.MutateNodes(r, rarity)
+}
+
+// Drops all edges to the node at index k of the left layer and shifts the edges to the nodes after it.
+func (l *InferredLayer) removeInputs(k int) {
+	for i := range l.Nodes {
+		inputs := l.Nodes[i].Inputs[:0]
+		for _, e := range l.Nodes[i].Inputs {
+			if e.Index == k {
+				continue
+			}
+			if e.Index > k {
+				e.Index--
+			}
+			inputs = append(inputs, e)
+		}
+		l.Nodes[i].Inputs = inputs
+	}
+}
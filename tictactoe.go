@@ -0,0 +1,67 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// Rewards networks for placing exactly one mark (1) on an empty square (0) of a board where the other squares are
+// either empty or taken (2). Every other output should be 0. A random bonus of up to 9 is added to every score to
+// break ties between equally good networks.
+type TicTacToeFitness struct {
+	// If set, the random bonus is left out, so the same output for the same input always gets the same score, e.g. to
+	// compare networks reliably.
+	Deterministic bool
+}
+
+func (f TicTacToeFitness) Evaluate(r *rand.Rand, input StaticLayer, output []byte) (int, error) {
+	if err := CheckLengths(input, output); err != nil {
+		return 0, err
+	}
+	move := -1
+	score := 0
+	zeroes := 0
+	for i, n := range output {
+		if n == 0 {
+			zeroes++
+		} else if n == 1 {
+			if move != -1 {
+				// illegal move - only one per turn
+				score -= 10
+				continue
+			}
+			move = i
+			score += 100
+		} else {
+			score -= 5 + int(n)
+		}
+	}
+	score += zeroes * 7
+	if zeroes == 8 && move != -1 && input[move] == 0 {
+		score += 100
+	}
+	if f.Deterministic {
+		return score, nil
+	}
+	return score + r.Intn(10), nil
+}
+
+// The demo's task as an Environment: boards where each square but the last is taken with a probability of 1/2, scored
+// like TicTacToeFitness.
+type TicTacToe struct {
+	// Like TicTacToeFitness.Deterministic.
+	Deterministic bool
+}
+
+func (TicTacToe) Reset(r *rand.Rand) []byte {
+	board := make([]byte, 9)
+	for i := range board {
+		if r.Intn(2) == 0 && i < 8 {
+			board[i] = 2
+		}
+	}
+	return board
+}
+
+func (t TicTacToe) Score(r *rand.Rand, input, output []byte) (int, error) {
+	return TicTacToeFitness{Deterministic: t.Deterministic}.Evaluate(r, input, output)
+}
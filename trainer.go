@@ -0,0 +1,664 @@
+package neural
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Evolves a population of networks that all read from the same input layer. All randomness comes from Rand, so two
+// trainers with identically seeded sources and the same configuration evolve identical populations.
+type Trainer struct {
+	// The source of all randomness used by the trainer. Each network is evaluated with its own source, seeded from
+	// this one, so the results don't depend on the number of workers.
+	Rand *rand.Rand
+	// The input layer that every network is rooted at.
+	Input StaticLayer
+	// Creates a new random network rooted at Input.
+	NewNetwork func(r *rand.Rand) *InferredLayer
+	// Prepares Input for the next episode.
+	Episode func(r *rand.Rand, input StaticLayer)
+	// Scores the output of a network for the current input.
+	Fitness Fitness
+	// If set, used instead of Episode and Fitness: every episode copies the result of Reset into Input, and networks
+	// are scored with Score. MultiFitness still takes precedence for scoring.
+	Environment Environment
+	// If set, used instead of Fitness to score networks on several objectives. The networks are then ranked by Pareto
+	// dominance (see ParetoNetwork), and their Score is their rank, from PopulationSize for the best network down.
+	// PreserveElites has no effect, since ranks are relative to the rest of the generation.
+	MultiFitness MultiFitness
+	// The Pareto front of the last generation when MultiFitness is set.
+	Front []ParetoNetwork
+	// If non-zero, every node of the hidden inferred layers of a network outputs 0 with this probability in every
+	// evaluation, which favors networks that don't depend on any single node. The output layer and layers below the
+	// chain of inferred layers aren't affected, and neither are Test, Infer and GetValues in general. Makes scores
+	// random, so it shouldn't be combined with CacheScores.
+	Dropout float64
+	// If non-zero, Complexity times the number of edges of a network (see ParamCount) is subtracted from its score
+	// every generation, which favors smaller networks. Not used with MultiFitness.
+	Complexity float64
+
+	// Number of episodes every network is scored on per generation, and how their scores are combined into the
+	// network's score. Defaults to the sum over 100 episodes with NewTrainer.
+	Episodes  int
+	Reduction Reduction
+	// Number of networks in the population.
+	PopulationSize int
+	// Number of top networks (elites) that survive each generation unchanged.
+	EliteCount int
+	// If set, elites keep the score they got when they were last evaluated instead of being scored again, so that the
+	// best score never decreases. This is only meaningful if scores are comparable between generations.
+	PreserveElites bool
+	// Mutated copies of the top networks (in rank order) that are placed after the survivors.
+	// The rest of the population is replaced with new random networks.
+	Offspring []Offspring
+	// If set, the parent of every offspring is picked with this selector instead of by rank, so that e.g. the first
+	// Offspring entry describes how many copies to make with its rarity rather than copies of the top network.
+	Selector Selector
+	// If set, networks that are structurally identical to a network earlier in the population (see Hash) are replaced
+	// with new random networks before each generation is evaluated, so the same network isn't scored more than once.
+	// The number of networks replaced is reported in Duplicates.
+	Deduplicate bool
+	Duplicates  int
+	// If set, the task is asserted to be deterministic: Episode (or Environment.Reset) only depends on the source it's
+	// given, and Fitness (or Environment.Score) doesn't use its source at all. The episodes of every generation are then
+	// generated from a source seeded with EpisodeSeed instead of from Rand, so they're the same in every generation,
+	// and networks that were scored in the previous generation (e.g. elites and offspring that mutations left
+	// unchanged) keep their score instead of being evaluated again. Networks are identified by their Hash. The number
+	// of networks that kept their score is reported in CacheHits. Not used with MultiFitness, and pointless with the
+	// random bonus of TicTacToeFitness.
+	CacheScores bool
+	EpisodeSeed int64
+	CacheHits   int
+	// If set, adjusts the rarity of every offspring's mutations from generation to generation. Defaults to
+	// ConstantSchedule.
+	Schedule MutationSchedule
+
+	// If set, the best network of every generation is also scored on this data with Dataset.Score. The result is only
+	// reported in TestScore and Log and doesn't affect selection, so it can be used to detect overfitting.
+	Test Dataset
+	// The score of the last generation's best network on Test.
+	TestScore int
+	// The stats of the last generation, which cover more than OnGeneration reports.
+	Stats GenerationStats
+	// If set, the highest scoring networks of every generation are offered to this hall of fame.
+	HallOfFame *HallOfFame
+
+	// If set, called after every generation with the best, average and worst scores of the population, excluding
+	// networks that failed to be evaluated, along with the best network.
+	OnGeneration func(gen int, best, avg, worst int, bestNet Layer)
+	// If set, called after OnGeneration with the best network and its values for the last episode's Input whenever
+	// the best network differs (see Hash) from the best network of the previous generation, or after every generation
+	// if OnBestEveryGeneration is set. The values may be kept and modified.
+	OnBest                func(gen int, net Layer, output []byte)
+	OnBestEveryGeneration bool
+	// If set, a CSV row with the generation, the best, average and worst scores as for OnGeneration, the Diversity of
+	// the population, the milliseconds elapsed since the first generation started, and the duration in milliseconds and
+	// the evaluations per second of the generation (see GenerationStats) is written here after every generation,
+	// preceded by a header row. If Test is set, the row ends with TestScore. If writing fails, StepContext returns the
+	// error and Run stops with it.
+	Log io.Writer
+
+	// If non-zero, Run stops after this many generations in a row without the best score improving on the best score
+	// so far by more than MinDelta.
+	Patience int
+	MinDelta int
+	// If set, the population is shaken up when the best score stops improving. Off by default.
+	Catastrophe *Catastrophe
+
+	// If both are set, the population is saved to Checkpoint with SavePopulation after every CheckpointEvery
+	// generations, so that training can be resumed after a crash. If saving fails, StepContext returns the error and Run
+	// stops with it. If CompressCheckpoint is set, SavePopulationCompressed is used instead.
+	Checkpoint         string
+	CheckpointEvery    int
+	CompressCheckpoint bool
+
+	// If non-zero, a network whose evaluations (GetValues, not Fitness) take longer than this in total in a generation
+	// is given FailedScore and isn't evaluated on the remaining episodes, so that e.g. a network that grew huge can't
+	// stall the generation. An evaluation in progress can't be interrupted, so a network can take up to one evaluation
+	// longer than this. The number of networks that timed out is reported in Timeouts, and each of them is passed to
+	// OnTimeout if it's set. Since timing varies from run to run, training with a timeout isn't reproducible.
+	EvalTimeout time.Duration
+	Timeouts    int
+	OnTimeout   func(net Layer, elapsed time.Duration)
+
+	// Number of goroutines that evaluate the population and build the offspring in parallel. Defaults to
+	// runtime.NumCPU().
+	Workers int
+
+	// The number of generations that have completed.
+	Generation int
+	// The current population, sorted by score after each generation.
+	Population []ScoredLayer
+	// The number of networks at the start of Population that are elites from the previous generation.
+	elites int
+	// The scores of the previous generation by network hash, before Complexity is applied, when CacheScores is set.
+	cache map[uint64]int
+	// The best score that counts as progress, and the number of generations since it was set.
+	plateau, stagnant int
+	tracking          bool
+	// The hash of the best network last passed to OnBest, and whether there was one.
+	bestHash uint64
+	reported bool
+	// When the first generation started, and whether the CSV header has been written to Log.
+	start  time.Time
+	logged bool
+}
+
+// Replaces part of a population that has stopped improving with new random networks, and/or temporarily boosts the
+// mutation rate, to help evolution escape local optima.
+type Catastrophe struct {
+	// The number of generations in a row without the best score improving by more than MinDelta before the
+	// catastrophe strikes. It strikes again every After generations until the best score improves.
+	After int
+	// The fraction of the next generation, counted from the bottom, that is replaced with new random networks. Elites
+	// are never replaced.
+	Fraction float64
+	// If non-zero, the offspring of a generation hit by the catastrophe are mutated with this rarity instead of their
+	// own.
+	Rarity int
+}
+
+// How the scores of a network's episodes are combined into its score.
+type Reduction byte
+
+const (
+	// The sum of the scores.
+	ReduceSum Reduction = iota
+	// The mean of the scores, rounded towards zero, which keeps scores comparable when Episodes changes.
+	ReduceMean
+	// The lowest score, which rewards networks that do well on every episode rather than on average.
+	ReduceMin
+)
+
+// Combines the score of an episode, counting from 0, with the combined scores of the episodes before it.
+func (r Reduction) add(acc, score, episode int) int {
+	if r == ReduceMin {
+		if episode == 0 {
+			return score
+		}
+		return min(acc, score)
+	}
+	return acc + score
+}
+
+// Returns the final score for the combined scores of n episodes.
+func (r Reduction) finish(acc, n int) int {
+	if r == ReduceMean && n > 0 {
+		return acc / n
+	}
+	return acc
+}
+
+// Creates a trainer where all randomness derives from seed, configured with the selection scheme of the demo. Input,
+// NewNetwork, and either Episode and Fitness or Environment still need to be set.
+func NewTrainer(seed int64) *Trainer {
+	return &Trainer{
+		Rand:           rand.New(rand.NewSource(seed)),
+		Episodes:       100,
+		PopulationSize: 200,
+		EliteCount:     10,
+		Offspring: []Offspring{
+			// 10 copies of the top network.
+			{Copies: 10, Rarity: 5000},
+			// 5 copies of 2nd and 3rd.
+			{Copies: 5, Rarity: 1000},
+			{Copies: 5, Rarity: 500},
+		},
+	}
+}
+
+// Mutated copies of a single top network.
+type Offspring struct {
+	Copies int
+	Rarity int
+	// If non-zero, the connectivity of the copies is also mutated with this rarity (see MutateEdges).
+	EdgeRarity int
+	// If non-zero, the hidden layer sizes of the copies are also mutated with this rarity (see MutateNodes).
+	NodeRarity int
+}
+
+// Replaces the population with pop, e.g. one loaded with LoadPopulation, so that training continues from it. The
+// networks are changed to read from Input. If pop is larger than PopulationSize, only the first PopulationSize networks
+// are kept, which are the best ones if pop is sorted like Population. If it's smaller, it is topped up with new random
+// networks at the start of the next generation.
+func (t *Trainer) Resume(pop []ScoredLayer) error {
+	if len(pop) > t.PopulationSize {
+		pop = pop[:t.PopulationSize]
+	}
+	for i := range pop {
+		if err := setInput(pop[i].InferredLayer, t.Input); err != nil {
+			return fmt.Errorf("network %d: %w", i, err)
+		}
+	}
+	t.Population = append([]ScoredLayer(nil), pop...)
+	// None of the networks have been evaluated by this trainer.
+	t.elites = 0
+	return nil
+}
+
+// Runs one generation and returns the highest scoring network. Errors are discarded, so a failure to save a checkpoint
+// or write to Log goes unnoticed, and for an empty population the result has no network; use StepContext to get them.
+func (t *Trainer) Step() ScoredLayer {
+	best, _ := t.StepContext(context.Background())
+	return best
+}
+
+// Like Step, but returns errors, and gives up on the generation if ctx is done before the population has been
+// evaluated. The error is then ctx.Err(), and the population is left as it was except for its scores. If saving a
+// checkpoint or writing to Log fails, the error is returned along with the best network of the completed generation.
+// An empty population, i.e. a PopulationSize of 0 with no resumed networks, is an error.
+func (t *Trainer) StepContext(ctx context.Context) (ScoredLayer, error) {
+	if t.Rand == nil {
+		t.Rand = newRand()
+	}
+	stepStart := time.Now()
+	if t.start.IsZero() {
+		t.start = stepStart
+	}
+	for len(t.Population) < t.PopulationSize {
+		t.Population = append(t.Population, ScoredLayer{InferredLayer: t.NewNetwork(t.Rand)})
+	}
+	pop := t.Population
+	if len(pop) == 0 {
+		return ScoredLayer{}, errors.New("population is empty")
+	}
+
+	// Preserved elites are skipped during evaluation.
+	skip := 0
+	if t.PreserveElites && t.MultiFitness == nil {
+		skip = min(t.elites, len(pop))
+	}
+	if t.Deduplicate {
+		t.Duplicates = 0
+		seen := make(map[uint64]bool, len(pop))
+		for i := range pop {
+			h := Hash(pop[i].InferredLayer)
+			// Preserved elites keep their scores, so they're never replaced.
+			if seen[h] && i >= skip {
+				pop[i] = ScoredLayer{InferredLayer: t.NewNetwork(t.Rand)}
+				t.Duplicates++
+				continue
+			}
+			seen[h] = true
+		}
+	}
+	for i := skip; i < len(pop); i++ {
+		pop[i].Score = 0
+	}
+	// Networks with a cached score are skipped like preserved elites.
+	var hashes []uint64
+	cached := make([]bool, len(pop))
+	caching := t.CacheScores && t.MultiFitness == nil
+	if caching {
+		t.CacheHits = 0
+		hashes = make([]uint64, len(pop))
+		for i := skip; i < len(pop); i++ {
+			hashes[i] = Hash(pop[i].InferredLayer)
+			if score, ok := t.cache[hashes[i]]; ok {
+				pop[i].Score, cached[i] = score, true
+				t.CacheHits++
+			}
+		}
+	}
+	episodeRand := t.Rand
+	if caching {
+		episodeRand = rand.New(rand.NewSource(t.EpisodeSeed))
+	}
+	workers := t.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	srcs := make([]splitMix64, len(pop))
+	rands := make([]*rand.Rand, len(pop))
+	for j := range rands {
+		srcs[j].Seed(t.Rand.Int63())
+		rands[j] = rand.New(&srcs[j])
+	}
+	failed := make([]bool, len(pop))
+	var elapsed []time.Duration
+	if t.EvalTimeout > 0 {
+		elapsed = make([]time.Duration, len(pop))
+	}
+	var objectives [][]int
+	if t.MultiFitness != nil {
+		objectives = make([][]int, len(pop))
+	}
+	evals := make([]int, workers)
+	for i := 0; i < t.Episodes; i++ {
+		if err := ctx.Err(); err != nil {
+			return ScoredLayer{}, err
+		}
+		if t.Environment != nil {
+			if err := t.Input.Set(t.Environment.Reset(episodeRand)); err != nil {
+				return ScoredLayer{}, fmt.Errorf("environment input: %w", err)
+			}
+		} else {
+			t.Episode(episodeRand, t.Input)
+		}
+		// Each worker owns every nth network so scores can be accumulated without locking.
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				// Counted locally so that workers don't write to shared memory for every evaluation.
+				var out []byte
+				var n int
+				defer func() { evals[w] += n }()
+				for j := skip + w; j < len(pop); j += workers {
+					if failed[j] || cached[j] {
+						continue
+					}
+					n++
+					out = resize(out, pop[j].Size())
+					var start time.Time
+					if elapsed != nil {
+						start = time.Now()
+					}
+					if t.Dropout > 0 {
+						dropoutValues(rands[j], pop[j].InferredLayer, t.Dropout, out)
+					} else {
+						pop[j].GetValuesInto(out)
+					}
+					if elapsed != nil {
+						if elapsed[j] += time.Since(start); elapsed[j] > t.EvalTimeout {
+							failed[j] = true
+							continue
+						}
+					}
+					if objectives != nil {
+						scores, err := t.MultiFitness.Evaluate(rands[j], t.Input, out)
+						if err != nil || objectives[j] != nil && len(scores) != len(objectives[j]) {
+							failed[j] = true
+							continue
+						}
+						if objectives[j] == nil {
+							objectives[j] = make([]int, len(scores))
+						}
+						for k, score := range scores {
+							objectives[j][k] = t.Reduction.add(objectives[j][k], score, i)
+						}
+						continue
+					}
+					var score int
+					var err error
+					if t.Environment != nil {
+						score, err = t.Environment.Score(rands[j], t.Input, out)
+					} else {
+						score, err = t.Fitness.Evaluate(rands[j], t.Input, out)
+					}
+					if err != nil {
+						failed[j] = true
+						continue
+					}
+					pop[j].Score = t.Reduction.add(pop[j].Score, score, i)
+				}
+			}(w)
+		}
+		wg.Wait()
+	}
+	t.Timeouts = 0
+	if objectives != nil {
+		failMismatched(objectives, failed)
+	}
+	var cache map[uint64]int
+	if caching {
+		cache = make(map[uint64]int, len(pop))
+	}
+	for j := skip; j < len(pop); j++ {
+		if elapsed != nil && elapsed[j] > t.EvalTimeout {
+			t.Timeouts++
+			if t.OnTimeout != nil {
+				t.OnTimeout(pop[j], elapsed[j])
+			}
+		}
+		if failed[j] {
+			pop[j].Score = FailedScore
+			continue
+		}
+		if objectives != nil {
+			for k := range objectives[j] {
+				objectives[j][k] = t.Reduction.finish(objectives[j][k], t.Episodes)
+			}
+			continue
+		}
+		if !cached[j] {
+			pop[j].Score = t.Reduction.finish(pop[j].Score, t.Episodes)
+		}
+		if caching {
+			cache[hashes[j]] = pop[j].Score
+		}
+		if t.Complexity != 0 {
+			pop[j].Score -= int(t.Complexity * float64(ParamCount(pop[j].InferredLayer)))
+		}
+	}
+	if caching {
+		t.cache = cache
+	}
+	if objectives != nil {
+		t.Front = rankPareto(pop, objectives, failed)
+	}
+
+	// Find the highest scoring networks.
+	sort.Slice(pop, func(i, j int) bool {
+		return pop[i].Score > pop[j].Score
+	})
+	best := pop[0]
+	if t.HallOfFame != nil {
+		for _, p := range pop[:min(t.HallOfFame.Size, len(pop))] {
+			t.HallOfFame.Add(p)
+		}
+	}
+	// Scoring on Test overwrites Input, so the output for OnBest is computed first.
+	var bestOutput []byte
+	if t.OnBest != nil {
+		bestOutput = best.GetValues()
+	}
+	if t.Test != nil {
+		score, err := t.Test.Score(t.Input, best)
+		if err != nil {
+			score = FailedScore
+		}
+		t.TestScore = score
+	}
+	if !t.tracking || best.Score > t.plateau+t.MinDelta {
+		t.tracking, t.plateau, t.stagnant = true, best.Score, 0
+	} else {
+		t.stagnant++
+	}
+	if t.Schedule != nil {
+		t.Schedule.Update(t.stagnant == 0)
+	}
+	c := t.Catastrophe
+	catastrophe := c != nil && c.After > 0 && t.stagnant > 0 && t.stagnant%c.After == 0
+
+	// The next generation is built separately since selectors may pick parents from anywhere in the population.
+	next := make([]ScoredLayer, len(pop))
+	n := copy(next, pop[:min(t.EliteCount, len(pop))])
+	t.elites = n
+	// Parents, rarities and random sources are picked up front so that the offspring can be built in parallel while
+	// staying the same regardless of the number of workers. Parents are only read, so several offspring can share one.
+	type child struct {
+		parent, rarity int
+		o              Offspring
+	}
+	var children []child
+	for rank, o := range t.Offspring {
+		for i := 0; i < o.Copies && n+len(children) < len(next); i++ {
+			parent := rank
+			if t.Selector != nil {
+				parent = t.Selector.Select(t.Rand, pop)
+			}
+			rarity := o.Rarity
+			if t.Schedule != nil {
+				rarity = t.Schedule.Rarity(rarity)
+			}
+			if catastrophe && c.Rarity > 0 {
+				rarity = c.Rarity
+			}
+			children = append(children, child{parent, rarity, o})
+		}
+	}
+	for k := range children {
+		srcs[k].Seed(t.Rand.Int63())
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for k := w; k < len(children); k += workers {
+				ch, r := children[k], rands[k]
+				next[n+k] = ScoredLayer{InferredLayer: pop[ch.parent].CopyInferred()}
+				next[n+k].Mutate(r, ch.rarity)
+				if ch.o.NodeRarity > 0 {
+					next[n+k].MutateNodes(r, ch.o.NodeRarity)
+				}
+				if ch.o.EdgeRarity > 0 {
+					next[n+k].MutateEdges(r, ch.o.EdgeRarity)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	n += len(children)
+	if catastrophe {
+		n = min(n, max(t.elites, len(next)-int(c.Fraction*float64(len(next)))))
+	}
+	// Remaining bottom dies.
+	for ; n < len(next); n++ {
+		next[n] = ScoredLayer{InferredLayer: t.NewNetwork(t.Rand)}
+	}
+	t.Population = next
+	t.Generation++
+	if t.Checkpoint != "" && t.CheckpointEvery > 0 && t.Generation%t.CheckpointEvery == 0 {
+		save := SavePopulation
+		if t.CompressCheckpoint {
+			save = SavePopulationCompressed
+		}
+		if err := save(t.Checkpoint, t.Population); err != nil {
+			return best, err
+		}
+	}
+	t.Stats = generationStats(pop)
+	t.Stats.Generation = t.Generation
+	t.Stats.Elapsed = time.Since(stepStart)
+	for _, n := range evals {
+		t.Stats.Evaluations += n
+	}
+	if s := t.Stats.Elapsed.Seconds(); s > 0 {
+		t.Stats.EvalsPerSecond = float64(t.Stats.Evaluations) / s
+	}
+	if t.OnGeneration != nil {
+		t.OnGeneration(t.Generation, best.Score, t.Stats.Average, t.Stats.Worst, &best)
+	}
+	if t.OnBest != nil {
+		if h := Hash(&best); t.OnBestEveryGeneration || !t.reported || h != t.bestHash {
+			t.bestHash, t.reported = h, true
+			t.OnBest(t.Generation, &best, bestOutput)
+		}
+	}
+	if t.Log != nil {
+		if err := t.log(best.Score, t.Stats.Average, t.Stats.Worst, Diversity(pop)); err != nil {
+			return best, err
+		}
+	}
+	return best, nil
+}
+
+// Writes a row of generation metrics to Log.
+func (t *Trainer) log(best, avg, worst int, diversity float64) error {
+	w := csv.NewWriter(t.Log)
+	if !t.logged {
+		header := []string{"gen", "best", "avg", "worst", "diversity", "elapsed_ms", "gen_ms", "evals_per_sec"}
+		if t.Test != nil {
+			header = append(header, "test")
+		}
+		w.Write(header)
+		t.logged = true
+	}
+	row := []string{
+		strconv.Itoa(t.Generation),
+		strconv.Itoa(best),
+		strconv.Itoa(avg),
+		strconv.Itoa(worst),
+		strconv.FormatFloat(diversity, 'f', 4, 64),
+		strconv.FormatInt(time.Since(t.start).Milliseconds(), 10),
+		strconv.FormatInt(t.Stats.Elapsed.Milliseconds(), 10),
+		strconv.FormatFloat(t.Stats.EvalsPerSecond, 'f', 0, 64),
+	}
+	if t.Test != nil {
+		row = append(row, strconv.Itoa(t.TestScore))
+	}
+	w.Write(row)
+	w.Flush()
+	return w.Error()
+}
+
+// Summarizes the scores of a generation.
+type GenerationStats struct {
+	Generation int
+	// The scores of the population, ignoring networks that failed to be evaluated. They're all FailedScore if every
+	// network failed. The median of an even number of scores is the average of the middle two.
+	Best, Average, Median, Worst int
+	// The number of networks that failed to be evaluated.
+	Failed int
+	// How long the generation took, from the start of Step to just before OnGeneration is called, and the number of
+	// times a network was evaluated (once per network and episode, except for networks that were skipped) per second of
+	// it.
+	Elapsed        time.Duration
+	Evaluations    int
+	EvalsPerSecond float64
+}
+
+// Returns the stats of a sorted population, except for the generation and its timing.
+func generationStats(pop []ScoredLayer) GenerationStats {
+	// Failed networks sort last.
+	n := len(pop)
+	for n > 0 && pop[n-1].Score == FailedScore {
+		n--
+	}
+	s := GenerationStats{Failed: len(pop) - n}
+	if n == 0 {
+		s.Best, s.Average, s.Median, s.Worst = FailedScore, FailedScore, FailedScore, FailedScore
+		return s
+	}
+	var sum int
+	for _, p := range pop[:n] {
+		sum += p.Score
+	}
+	s.Best, s.Average, s.Worst = pop[0].Score, sum/n, pop[n-1].Score
+	s.Median = pop[n/2].Score
+	if n%2 == 0 {
+		s.Median = (pop[n/2-1].Score + pop[n/2].Score) / 2
+	}
+	return s
+}
+
+// Runs generations until the best score has stopped improving (see Patience) or ctx is done, and returns the highest
+// scoring network seen. Generation holds the generation it stopped at. Cancellation is checked between episodes, so a
+// cancelled run returns promptly with the best network of the completed generations and ctx.Err().
+func (t *Trainer) Run(ctx context.Context) (*ScoredLayer, error) {
+	var best *ScoredLayer
+	for {
+		gen, err := t.StepContext(ctx)
+		if gen.InferredLayer != nil && (best == nil || gen.Score > best.Score) {
+			best = &gen
+		}
+		if err != nil {
+			return best, err
+		}
+		if t.Patience > 0 && t.stagnant >= t.Patience {
+			return best, nil
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package neural
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// Returns a small tic-tac-toe trainer like the demo's that runs a generation quickly.
+func newTestTrainer(seed int64) *Trainer {
+	t := NewTrainer(seed)
+	t.Input = make(StaticLayer, 9)
+	t.NewNetwork = func(r *rand.Rand) *InferredLayer {
+		hidden := NewFullyConnectedLayerWithRand(r, t.Input, 9)
+		return NewFullyConnectedLayerWithRand(r, hidden, len(t.Input))
+	}
+	t.Environment = TicTacToe{}
+	t.PopulationSize = 50
+	t.Episodes = 10
+	return t
+}
+
+func TestTrainerDeterministic(t *testing.T) {
+	var outputs [][]byte
+	for _, workers := range []int{1, 4} {
+		tr := newTestTrainer(42)
+		tr.Workers = workers
+		var best ScoredLayer
+		for i := 0; i < 5; i++ {
+			best = tr.Step()
+		}
+		outputs = append(outputs, best.GetValues())
+	}
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Errorf("best network outputs %v with 1 worker and %v with 4", outputs[0], outputs[1])
+	}
+}
+
+func TestPreserveElites(t *testing.T) {
+	tr := newTestTrainer(1)
+	tr.PreserveElites = true
+	prev := tr.Step().Score
+	for i := 0; i < 20; i++ {
+		best := tr.Step().Score
+		if best < prev {
+			t.Fatalf("generation %d: best score %d is lower than %d before", tr.Generation, best, prev)
+		}
+		prev = best
+	}
+}
+
+func TestTrainerEmptyInput(t *testing.T) {
+	tr := newTestTrainer(1)
+	tr.Input = StaticLayer{}
+	tr.Environment = nil
+	tr.Episode = func(r *rand.Rand, input StaticLayer) {}
+	tr.Fitness = TicTacToeFitness{}
+	for i := 0; i < 3; i++ {
+		if best := tr.Step(); best.Score == FailedScore || len(best.GetValues()) != 0 {
+			t.Fatalf("generation %d: best network scored %d with values %v", tr.Generation, best.Score, best.GetValues())
+		}
+	}
+}
+
+func TestOnBestOutput(t *testing.T) {
+	tr := newTestTrainer(1)
+	var last []byte
+	tr.Environment = nil
+	tr.Episode = func(r *rand.Rand, input StaticLayer) {
+		for i := range input {
+			input[i] = byte(r.Intn(3))
+		}
+		last = append(last[:0], input...)
+	}
+	tr.Fitness = TicTacToeFitness{}
+	// Scoring on Test leaves a different board in Input, which mustn't affect the output passed to OnBest.
+	tr.Test = Dataset{{Input: []byte{1, 1, 1, 1, 1, 1, 1, 1, 1}, Target: make([]byte, 9)}}
+	calls := 0
+	tr.OnBest = func(gen int, net Layer, output []byte) {
+		calls++
+		want, err := Infer(DeepCopy(net), last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(output, want) {
+			t.Errorf("generation %d: output %v, want %v for the last episode's input %v", gen, output, want, last)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		tr.Step()
+	}
+	if calls == 0 {
+		t.Error("OnBest was never called")
+	}
+
+	tr.OnBestEveryGeneration = true
+	calls = 0
+	for i := 0; i < 5; i++ {
+		tr.Step()
+	}
+	if calls != 5 {
+		t.Errorf("OnBest was called %d times in 5 generations with OnBestEveryGeneration", calls)
+	}
+}
+
+func TestLogTestScore(t *testing.T) {
+	tr := newTestTrainer(1)
+	tr.Test = Dataset{{Input: make([]byte, 9), Target: []byte{1, 0, 0, 0, 0, 0, 0, 0, 0}}}
+	var log bytes.Buffer
+	tr.Log = &log
+	for i := 0; i < 3; i++ {
+		tr.Step()
+	}
+	rows, err := csv.NewReader(&log).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][len(rows[0])-1] != "test" {
+		t.Fatalf("log has no test column: %v", rows)
+	}
+	if got := rows[3][len(rows[3])-1]; got != strconv.Itoa(tr.TestScore) {
+		t.Errorf("last row has test score %s, want %d", got, tr.TestScore)
+	}
+}
+
+func TestStepContextErrors(t *testing.T) {
+	tr := newTestTrainer(1)
+	tr.Checkpoint = filepath.Join(t.TempDir(), "missing", "checkpoint")
+	tr.CheckpointEvery = 1
+	best, err := tr.StepContext(context.Background())
+	if err == nil {
+		t.Error("saving a checkpoint to a missing directory didn't fail")
+	}
+	if best.InferredLayer == nil {
+		t.Error("no best network along with the error")
+	}
+
+	tr = newTestTrainer(1)
+	tr.PopulationSize = 0
+	if _, err := tr.StepContext(context.Background()); err == nil {
+		t.Error("an empty population didn't fail")
+	}
+}
+
+// Scores the output on two or three objectives depending on its first value.
+type unevenFitness struct{}
+
+func (unevenFitness) Evaluate(r *rand.Rand, input StaticLayer, output []byte) ([]int, error) {
+	scores := []int{int(output[0]), int(output[1])}
+	if output[0]&1 == 1 {
+		scores = append(scores, int(output[2]))
+	}
+	return scores, nil
+}
+
+func TestMultiFitnessMismatch(t *testing.T) {
+	tr := newTestTrainer(1)
+	tr.Environment = nil
+	tr.Episode = func(r *rand.Rand, input StaticLayer) {}
+	tr.MultiFitness = unevenFitness{}
+	for i := 0; i < 5; i++ {
+		tr.Step()
+		if len(tr.Front) == 0 {
+			t.Fatalf("generation %d: the Pareto front is empty", tr.Generation)
+		}
+		for _, p := range tr.Front {
+			if n := len(p.Objectives); n != len(tr.Front[0].Objectives) {
+				t.Fatalf("generation %d: the Pareto front has networks with %d and %d objectives", tr.Generation, len(tr.Front[0].Objectives), n)
+			}
+		}
+	}
+}
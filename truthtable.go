@@ -0,0 +1,36 @@
+package neural
+
+import (
+	"fmt"
+)
+
+// The largest input size that TruthTable accepts, since the table has 2^n rows for an input of n values.
+var TruthTableLimit = 16
+
+// Evaluates a network for every input where each value is 0 or 1 and returns the inputs and outputs, in the order of
+// counting in binary with the first input value as the most significant bit. The network must read from a single input
+// layer, which is restored afterwards, and its size must not exceed TruthTableLimit. Like GetValuesBatch, the input
+// layer must not be in use by other goroutines meanwhile.
+func TruthTable(l Layer) ([][]byte, [][]byte, error) {
+	in, err := inputOf(l)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := len(in)
+	if n > TruthTableLimit {
+		return nil, nil, fmt.Errorf("input size %d exceeds the truth table limit of %d", n, TruthTableLimit)
+	}
+	buf := make([]byte, n<<n)
+	inputs := make([][]byte, 1<<n)
+	for k := range inputs {
+		inputs[k] = buf[k*n : (k+1)*n : (k+1)*n]
+		for i := range inputs[k] {
+			inputs[k][i] = byte(k>>(n-1-i)) & 1
+		}
+	}
+	outputs, err := GetValuesBatch(l, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputs, outputs, nil
+}
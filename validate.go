@@ -0,0 +1,222 @@
+package neural
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Checks that the network has no cycles, that every edge in the network refers to a node that exists in the layer it
+// reads from, and that every edge has a known operation. Errors identify the
+// offending layer by its depth, counting from 0 at the output layer.
+func Validate(l Layer) error {
+	if err := checkCycles(l); err != nil {
+		return err
+	}
+	depths := make(map[any]int)
+	Walk(l, func(depth int, l Layer) {
+		depths[layerKey(l)] = depth
+	})
+	// Layers are checked from the input up, since checking a layer asks for the size of the layers it reads from,
+	// which may not work if they're invalid themselves.
+	for _, l := range Layers(l) {
+		if err := validateLayer(l, depths[layerKey(l)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks a single layer, but not the layers it reads from.
+func validateLayer(l Layer, depth int) error {
+	switch l := l.(type) {
+	case *InferredLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+		return validateNodes(l.Nodes, l.Left.Size(), depth)
+	case *RecurrentLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+		return validateNodes(l.Nodes, l.Left.Size()+len(l.Nodes), depth)
+	case *MaxPoolLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+		if l.Window < 1 {
+			return fmt.Errorf("layer %d: window size %d must be at least 1", depth, l.Window)
+		}
+	case *NotLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+	case *ShiftLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+	case *LUTLayer:
+		if l.Left == nil {
+			return fmt.Errorf("layer %d: missing left layer", depth)
+		}
+		size := l.Left.Size()
+		for i, n := range l.Nodes {
+			if len(n.Inputs) > LUTBits {
+				return fmt.Errorf("layer %d, node %d: %d input bits exceed the limit of %d", depth, i, len(n.Inputs), LUTBits)
+			}
+			for j, in := range n.Inputs {
+				if in.Index < 0 || in.Index >= size {
+					return fmt.Errorf("layer %d, node %d, input %d: index %d is out of range [0, %d)", depth, i, j, in.Index, size)
+				}
+				if in.Bit > 7 {
+					return fmt.Errorf("layer %d, node %d, input %d: bit %d is out of range [0, 8)", depth, i, j, in.Bit)
+				}
+			}
+		}
+	case *ConcatLayer:
+		for i, c := range l.Layers {
+			if c == nil {
+				return fmt.Errorf("layer %d: missing concatenated layer %d", depth, i)
+			}
+		}
+	case *DAGLayer:
+		for i, left := range l.Lefts {
+			if left == nil {
+				return fmt.Errorf("layer %d: missing left layer %d", depth, i)
+			}
+		}
+		return validateNodes(l.Nodes, l.inSize(), depth)
+	}
+	return nil
+}
+
+// Checks that every node has a known activation and that every edge of the nodes refers to an index in [0, size) and
+// has a known operation.
+func validateNodes(nodes []Node, size, depth int) error {
+	for i, n := range nodes {
+		if n.Activation >= numActivations {
+			return fmt.Errorf("layer %d, node %d: unknown activation %d", depth, i, n.Activation)
+		}
+		for j, e := range n.Inputs {
+			if e.Index < 0 || e.Index >= size {
+				return fmt.Errorf("layer %d, node %d, edge %d: index %d is out of range [0, %d)", depth, i, j, e.Index, size)
+			}
+			if e.Op >= numOps {
+				return fmt.Errorf("layer %d, node %d, edge %d: unknown operation %d", depth, i, j, e.Op)
+			}
+		}
+	}
+	return nil
+}
+
+// Fixes the problems that Validate reports where it can, as a more forgiving alternative for networks from untrusted
+// sources, and returns the number of fixes made. Edges with an index out of range are dropped, since there's no
+// meaningful node to point them to instead, edges with an unknown operation are changed to OpAnd, nodes with an unknown
+// activation are changed to ActivationNone, and max-pool windows smaller than 1 are set to 1. A missing left or
+// concatenated layer can't be repaired and is returned as an error, after the rest of the network has been repaired.
+func Repair(l Layer) (int, error) {
+	fixed := 0
+	var err error
+	for _, l := range Layers(l) {
+		switch l := l.(type) {
+		case *InferredLayer:
+			if l.Left == nil {
+				err = errors.New("inferred layer is missing its left layer")
+				continue
+			}
+			if n := repairNodes(l.Nodes, l.Left.Size()); n > 0 {
+				fixed += n
+				l.Invalidate()
+			}
+		case *RecurrentLayer:
+			if l.Left == nil {
+				err = errors.New("recurrent layer is missing its left layer")
+				continue
+			}
+			fixed += repairNodes(l.Nodes, l.Left.Size()+len(l.Nodes))
+		case *MaxPoolLayer:
+			if l.Left == nil {
+				err = errors.New("max-pool layer is missing its left layer")
+				continue
+			}
+			if l.Window < 1 {
+				l.Window = 1
+				fixed++
+			}
+		case *NotLayer:
+			if l.Left == nil {
+				err = errors.New("not layer is missing its left layer")
+			}
+		case *ShiftLayer:
+			if l.Left == nil {
+				err = errors.New("shift layer is missing its left layer")
+			}
+		case *LUTLayer:
+			if l.Left == nil {
+				err = errors.New("lookup table layer is missing its left layer")
+				continue
+			}
+			fixed += repairLUTNodes(l.Nodes, l.Left.Size())
+		case *ConcatLayer:
+			for _, c := range l.Layers {
+				if c == nil {
+					err = errors.New("concat layer is missing a layer")
+				}
+			}
+		case *DAGLayer:
+			missing := false
+			for _, left := range l.Lefts {
+				missing = missing || left == nil
+			}
+			if missing {
+				err = errors.New("DAG layer is missing a left layer")
+				continue
+			}
+			fixed += repairNodes(l.Nodes, l.inSize())
+		}
+	}
+	return fixed, err
+}
+
+// Drops the inputs of the nodes whose index isn't in [0, size) or whose bit doesn't exist, along with the inputs
+// beyond the first LUTBits, returning the number of inputs dropped.
+func repairLUTNodes(nodes []LUTNode, size int) int {
+	fixed := 0
+	for i := range nodes {
+		inputs := nodes[i].Inputs[:0]
+		for _, in := range nodes[i].Inputs {
+			if in.Index < 0 || in.Index >= size || in.Bit > 7 || len(inputs) == LUTBits {
+				fixed++
+				continue
+			}
+			inputs = append(inputs, in)
+		}
+		nodes[i].Inputs = inputs
+	}
+	return fixed
+}
+
+// Drops the edges of the nodes whose index isn't in [0, size) and resets unknown operations and activations, returning
+// the number of nodes and edges changed.
+func repairNodes(nodes []Node, size int) int {
+	fixed := 0
+	for i := range nodes {
+		if nodes[i].Activation >= numActivations {
+			nodes[i].Activation = ActivationNone
+			fixed++
+		}
+		inputs := nodes[i].Inputs[:0]
+		for _, e := range nodes[i].Inputs {
+			if e.Index < 0 || e.Index >= size {
+				fixed++
+				continue
+			}
+			if e.Op >= numOps {
+				e.Op = OpAnd
+				fixed++
+			}
+			inputs = append(inputs, e)
+		}
+		nodes[i].Inputs = inputs
+	}
+	return fixed
+}
@@ -0,0 +1,28 @@
+package neural
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestValidateCorrupt(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	hidden := NewFullyConnectedLayerWithRand(r, make(StaticLayer, 3), 4)
+	l := NewFullyConnectedLayerWithRand(r, hidden, 2)
+	if err := Validate(l); err != nil {
+		t.Fatalf("valid network: %v", err)
+	}
+
+	hidden.Nodes[2].Inputs[1].Index = 3
+	want := "layer 1, node 2, edge 1: index 3 is out of range [0, 3)"
+	if err := Validate(l); err == nil || err.Error() != want {
+		t.Errorf("edge out of range: got error %v, want %q", err, want)
+	}
+	hidden.Nodes[2].Inputs[1].Index = 1
+
+	l.Nodes[1].Inputs[0].Op = numOps
+	want = "layer 0, node 1, edge 0: unknown operation 3"
+	if err := Validate(l); err == nil || err.Error() != want {
+		t.Errorf("unknown operation: got error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,122 @@
+package neural
+
+// Calls fn for every layer of a network, starting with l at depth 0 and continuing depth first with the layers that
+// each layer reads from at one more depth. Layers that are read from more than once (e.g. an input shared by both
+// sides of a ConcatLayer) are only visited the first time they're reached. A ScoredLayer is visited as its
+// InferredLayer.
+func Walk(l Layer, fn func(depth int, l Layer)) {
+	seen := make(map[any]bool)
+	var visit func(depth int, l Layer)
+	visit = func(depth int, l Layer) {
+		l = unwrap(l)
+		key := layerKey(l)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		fn(depth, l)
+		for _, left := range lefts(l) {
+			visit(depth+1, left)
+		}
+	}
+	visit(0, l)
+}
+
+// Returns the layers of a network, each after all the layers it reads from, so that for a chain of layers the input
+// comes first and l comes last. Like Walk, shared layers are only included once and a ScoredLayer is included as its
+// InferredLayer.
+func Layers(l Layer) []Layer {
+	var layers []Layer
+	seen := make(map[any]bool)
+	var visit func(l Layer)
+	visit = func(l Layer) {
+		l = unwrap(l)
+		key := layerKey(l)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		for _, left := range lefts(l) {
+			visit(left)
+		}
+		layers = append(layers, l)
+	}
+	visit(l)
+	return layers
+}
+
+// Replaces every input layer that a layer in the network reads from with the result of f.
+func mapInputs(l Layer, f func(StaticLayer) StaticLayer) {
+	replace := func(left *Layer) {
+		if s, ok := (*left).(StaticLayer); ok {
+			*left = f(s)
+		}
+	}
+	for _, l := range Layers(l) {
+		switch l := l.(type) {
+		case *InferredLayer:
+			replace(&l.Left)
+		case *RecurrentLayer:
+			replace(&l.Left)
+		case *MaxPoolLayer:
+			replace(&l.Left)
+		case *NotLayer:
+			replace(&l.Left)
+		case *ShiftLayer:
+			replace(&l.Left)
+		case *LUTLayer:
+			replace(&l.Left)
+		case *ConcatLayer:
+			for i := range l.Layers {
+				replace(&l.Layers[i])
+			}
+		case *DAGLayer:
+			for i := range l.Lefts {
+				replace(&l.Lefts[i])
+			}
+			l.Invalidate()
+		}
+	}
+}
+
+// Returns the layers that a layer reads from, in order. Missing left layers are left out.
+func lefts(l Layer) []Layer {
+	var ls []Layer
+	add := func(layers ...Layer) {
+		for _, left := range layers {
+			if left != nil {
+				ls = append(ls, left)
+			}
+		}
+	}
+	switch l := unwrap(l).(type) {
+	case *InferredLayer:
+		add(l.Left)
+	case *RecurrentLayer:
+		add(l.Left)
+	case *MaxPoolLayer:
+		add(l.Left)
+	case *NotLayer:
+		add(l.Left)
+	case *ShiftLayer:
+		add(l.Left)
+	case *LUTLayer:
+		add(l.Left)
+	case *ConcatLayer:
+		add(l.Layers...)
+	case *DAGLayer:
+		add(l.Lefts...)
+	}
+	return ls
+}
+
+// Returns the InferredLayer of a ScoredLayer, and any other layer as is.
+func unwrap(l Layer) Layer {
+	switch s := l.(type) {
+	case *ScoredLayer:
+		return s.InferredLayer
+	case ScoredLayer:
+		return s.InferredLayer
+	}
+	return l
+}
@@ -0,0 +1,134 @@
+package neural
+
+import (
+	"math/rand"
+)
+
+// The value types that wide layers can hold. The byte-based layers (Layer, InferredLayer, etc.) remain the default;
+// the wide layers are a parallel implementation for problems that need more than 8 bits per node.
+type Unsigned interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint
+}
+
+// Like Layer, but with values of type T.
+type WideLayer[T Unsigned] interface {
+	Copy() WideLayer[T]
+	GetValues() []T
+	Size() int
+}
+
+// Like Node, but with values of type T.
+type WideNode[T Unsigned] struct {
+	Inputs []WideEdge[T]
+	Bias   T
+}
+
+// Like Edge, but with masks of type T. Wide edges always use OpAnd.
+type WideEdge[T Unsigned] struct {
+	Index    int
+	And, Xor T
+}
+
+// Like InferredLayer, but with values of type T.
+type WideInferredLayer[T Unsigned] struct {
+	Nodes []WideNode[T]
+	Left  WideLayer[T]
+}
+
+func (l *WideInferredLayer[T]) Copy() WideLayer[T] {
+	nodes := make([]WideNode[T], len(l.Nodes))
+	for i, n := range l.Nodes {
+		nodes[i] = n
+		nodes[i].Inputs = append([]WideEdge[T](nil), n.Inputs...)
+	}
+	return &WideInferredLayer[T]{Nodes: nodes, Left: l.Left.Copy()}
+}
+
+func (l *WideInferredLayer[T]) GetValues() []T {
+	lv := l.Left.GetValues()
+	v := make([]T, len(l.Nodes))
+	for i, node := range l.Nodes {
+		v[i] = node.Bias
+		for _, input := range node.Inputs {
+			v[i] ^= lv[input.Index]&input.And ^ input.Xor
+		}
+	}
+	return v
+}
+
+// Like InferredLayer.Mutate: each edge and bias is mutated with a probability of 1/rarity.
+func (l *WideInferredLayer[T]) Mutate(r *rand.Rand, rarity int) {
+	for i := range l.Nodes {
+		if r.Intn(rarity) == 0 {
+			l.Nodes[i].Bias ^= 1 << uint(r.Intn(bitsOf[T]()))
+		}
+		for j := range l.Nodes[i].Inputs {
+			if r.Intn(rarity) != 0 {
+				continue
+			}
+			e := &l.Nodes[i].Inputs[j]
+			// Set and clear every bit with a probability of 1/256, like the byte-based mutation.
+			e.And = (e.And | rareBits[T](r)) &^ rareBits[T](r)
+			e.Xor = (e.Xor | rareBits[T](r)) &^ rareBits[T](r)
+		}
+	}
+	if m, ok := l.Left.(interface{ Mutate(*rand.Rand, int) }); ok {
+		m.Mutate(r, rarity)
+	}
+}
+
+func (l *WideInferredLayer[T]) Size() int {
+	return len(l.Nodes)
+}
+
+// Like StaticLayer, but with values of type T.
+type WideStaticLayer[T Unsigned] []T
+
+// Returns the layer itself, like StaticLayer.Copy.
+func (l WideStaticLayer[T]) Copy() WideLayer[T] {
+	return l
+}
+
+func (l WideStaticLayer[T]) GetValues() []T {
+	return l
+}
+
+func (l WideStaticLayer[T]) Size() int {
+	return len(l)
+}
+
+// Like NewFullyConnectedLayerWithRand, but for wide layers.
+func NewWideFullyConnectedLayer[T Unsigned](r *rand.Rand, left WideLayer[T], size int) *WideInferredLayer[T] {
+	checkSize(size)
+	l := &WideInferredLayer[T]{
+		Nodes: make([]WideNode[T], size),
+		Left:  left,
+	}
+	leftSize := left.Size()
+	for i := range l.Nodes {
+		edges := make([]WideEdge[T], leftSize)
+		for j := range edges {
+			edges[j] = WideEdge[T]{Index: j, And: T(r.Uint64()), Xor: T(r.Uint64())}
+		}
+		l.Nodes[i].Inputs = edges
+	}
+	return l
+}
+
+// Returns the number of bits in T.
+func bitsOf[T Unsigned]() int {
+	n := 0
+	for v := ^T(0); v != 0; v >>= 1 {
+		n++
+	}
+	return n
+}
+
+// Returns a value where each bit is set with a probability of 1/256.
+func rareBits[T Unsigned](r *rand.Rand) T {
+	v := ^T(0)
+	for i := 0; i < 8; i++ {
+		v &= T(r.Uint64())
+	}
+	return v
+}